@@ -3,18 +3,28 @@ package main
 import (
 	"context"
 	"flag"
-	"log"
+	"log/slog"
 	"os"
 
 	"github.com/yasv98/movies-api/cmd/runner"
+	"github.com/yasv98/movies-api/internal/config"
 )
 
-var configPath = flag.String("configPath", "config/config.yaml", "path to config file")
+var (
+	configPath = flag.String("configPath", "config/config.yaml", "path to config file, leave empty to configure from the environment and flags only")
+	overrides  = config.BindFlags(flag.CommandLine)
+)
 
 func main() {
 	flag.Parse()
-	if err := runner.Run(context.Background(), *configPath); err != nil {
-		log.Println(err)
+
+	opts := config.Options{
+		Path:      *configPath,
+		Environ:   os.Environ(),
+		Overrides: overrides,
+	}
+	if err := runner.Run(context.Background(), opts); err != nil {
+		slog.Error("exiting", "error", err)
 		os.Exit(1)
 	}
 }
@@ -2,58 +2,377 @@ package runner
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/yasv98/movies-api/internal/config"
 	"github.com/yasv98/movies-api/internal/delivery/http/handler"
+	"github.com/yasv98/movies-api/internal/delivery/http/middleware"
 	"github.com/yasv98/movies-api/internal/delivery/http/routes"
+	"github.com/yasv98/movies-api/internal/domain"
+	"github.com/yasv98/movies-api/internal/health"
+	"github.com/yasv98/movies-api/internal/logging"
+	"github.com/yasv98/movies-api/internal/metrics"
+	"github.com/yasv98/movies-api/internal/ratelimit"
 	"github.com/yasv98/movies-api/internal/repository/mongodb"
 	"github.com/yasv98/movies-api/internal/service"
+	"github.com/yasv98/movies-api/internal/tracing"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
-func Run(ctx context.Context, configPath string) error {
-	cfg, err := config.LoadConfig(configPath)
+// defaultShutdownTimeout is how long in-flight requests are given to finish
+// when no timeout is configured.
+const defaultShutdownTimeout = 15 * time.Second
+
+// disconnectTimeout bounds how long closing the Mongo client may take.
+const disconnectTimeout = 10 * time.Second
+
+// normalizeRetryInterval is how long to wait before retrying a failed
+// backfill of normalized movie fields.
+const normalizeRetryInterval = 30 * time.Second
+
+// tracingShutdownTimeout bounds how long flushing buffered spans may take.
+const tracingShutdownTimeout = 5 * time.Second
+
+// Run serves the API until ctx is done or the process receives SIGINT or
+// SIGTERM. On shutdown in-flight requests are drained first, then
+// background workers are stopped, the Mongo client is disconnected and
+// finally any buffered spans are flushed.
+func Run(ctx context.Context, configOpts config.Options) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg, err := config.Load(configOpts)
 	if err != nil {
 		return fmt.Errorf("load config: %w", err)
 	}
 
-	client, err := initializeMongoDB(ctx, cfg.MonogoDB.URI)
+	logLevel := new(slog.LevelVar)
+	logger, err := logging.New(os.Stdout, cfg.Logging, logLevel)
+	if err != nil {
+		return fmt.Errorf("set up logging: %w", err)
+	}
+	slog.SetDefault(logger)
+	ctx = logging.NewContext(ctx, logger)
+
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("set up tracing: %w", err)
+	}
+	defer func() {
+		// Flushed last so spans from the rest of shutdown are exported.
+		ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Error("error shutting down tracing", "error", err)
+		}
+	}()
+
+	appMetrics := metrics.New()
+
+	mongoOpts, err := mongoClientOptions(cfg.MonogoDB)
+	if err != nil {
+		return fmt.Errorf("mongo options: %w", err)
+	}
+	client, err := initializeMongoDB(
+		ctx,
+		mongoOpts,
+		combineCommandMonitors(appMetrics.CommandMonitor(), tracing.CommandMonitor()),
+		appMetrics.PoolMonitor(),
+	)
 	if err != nil {
 		return fmt.Errorf("initialize mongo db: %w", err)
 	}
 	defer func() {
+		// ctx is already cancelled when shutting down, so disconnecting
+		// gets a context of its own.
+		ctx, cancel := context.WithTimeout(context.Background(), disconnectTimeout)
+		defer cancel()
 		if err := client.Disconnect(ctx); err != nil {
-			log.Printf("error disconnecting mongo client: %v", err)
+			logger.Error("error disconnecting mongo client", "error", err)
 		}
 	}()
 
 	db := client.Database(cfg.MonogoDB.Database)
+	if err := mongodb.EnsureIndexes(ctx, db); err != nil {
+		return fmt.Errorf("ensure indexes: %w", err)
+	}
+
+	// Background workers outlive ctx so they are only stopped once requests
+	// that may depend on them have drained.
+	workers := newWorkers(ctx)
+	defer workers.Stop()
+
+	// Backfill normalized fields in the background. Autocomplete and people
+	// lookups rely on them, so the service reports itself not ready until
+	// the backfill has finished, retrying it if it fails.
+	normalized := health.NewStartup("normalized_fields")
+	workers.Go(func(ctx context.Context) {
+		for {
+			err := mongodb.SyncNormalizedFields(ctx, db)
+			if err == nil {
+				normalized.Done()
+				return
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			logging.FromContext(ctx).Error("error syncing normalized fields", "error", err)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(normalizeRetryInterval):
+			}
+		}
+	})
 
 	// Repository.
 	movieRepo := mongodb.NewMovieRepository(db)
 	commentRepo := mongodb.NewCommentRepository(db)
+	reactionRepo := mongodb.NewReactionRepository(db)
+	flagRepo := mongodb.NewFlagRepository(db)
+	moderationRepo := mongodb.NewModerationRepository(db)
+	ratingRepo := mongodb.NewRatingRepository(db)
+	userListRepo := mongodb.NewUserListRepository(db)
+	collectionRepo := mongodb.NewCollectionRepository(db)
+	statsRepo := mongodb.NewStatsRepository(db)
+	personRepo := mongodb.NewPersonRepository(db)
+	referenceRepo := mongodb.NewReferenceRepository(db)
+	idempotencyRepo := mongodb.NewIdempotencyRepository(db)
 
 	// Service.
-	movieUsecase := service.NewMovieService(movieRepo)
-	commentUsecase := service.NewCommentService(commentRepo)
+	movieUsecase := service.NewMovieService(movieRepo, service.NewAggregationRecommender(movieRepo))
+	ratingUsecase := service.NewRatingService(ratingRepo, movieRepo)
+	userListUsecase := service.NewUserListService(userListRepo, movieRepo)
+	collectionUsecase := service.NewCollectionService(collectionRepo, movieRepo)
+	statsUsecase := service.NewStatsService(statsRepo)
+	personUsecase := service.NewPersonService(personRepo)
+	referenceUsecase := service.NewReferenceService(referenceRepo)
+	workers.Go(func(ctx context.Context) {
+		referenceUsecase.Run(ctx, cfg.Reference.RefreshInterval)
+	})
+	contentFilter, err := newContentFilter(cfg.ContentFilter, commentRepo)
+	if err != nil {
+		return fmt.Errorf("content filter: %w", err)
+	}
+	commentUsecase := service.NewCommentService(commentRepo, reactionRepo, contentFilter)
+	idempotencyUsecase := service.NewIdempotencyService(idempotencyRepo, cfg.Idempotency.TTL)
+	moderationUsecase := service.NewModerationService(
+		commentRepo,
+		flagRepo,
+		moderationRepo,
+		cfg.Moderation.FlagThreshold,
+		cfg.Moderation.Moderators,
+	)
+
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.NewPolicy(cfg.RateLimit))
+
+	// Config reloads, on SIGHUP or when the config file changes.
+	configOpts.Logger = logger
+	reloader := config.NewReloader(configOpts, cfg, logger)
+	reloader.Subscribe(func(cfg *config.Config) (func(), error) {
+		// Everything that can fail is built before anything is applied, so
+		// a rejected reload leaves the running config untouched.
+		level, err := logging.ParseLevel(cfg.Logging.Level)
+		if err != nil {
+			return nil, err
+		}
+		contentFilter, err := newContentFilter(cfg.ContentFilter, commentRepo)
+		if err != nil {
+			return nil, fmt.Errorf("rebuild content filter: %w", err)
+		}
+		policy := ratelimit.NewPolicy(cfg.RateLimit)
+
+		return func() {
+			logLevel.Set(level)
+			moderationUsecase.SetPolicy(cfg.Moderation.FlagThreshold, cfg.Moderation.Moderators)
+			limiter.SetPolicy(policy)
+			commentUsecase.SetFilter(contentFilter)
+		}, nil
+	})
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+	workers.Go(func(ctx context.Context) {
+		if err := reloader.Watch(ctx, hangup, config.DefaultReloadDebounce); err != nil {
+			logging.FromContext(ctx).Error("error watching config", "error", err)
+		}
+	})
 
 	// Handler.
 	movieHandler := handler.NewMovieHandler(movieUsecase)
-	commentHandler := handler.NewCommentHandler(commentUsecase)
+	commentHandler := handler.NewCommentHandler(commentUsecase, idempotencyUsecase)
+	moderationHandler := handler.NewModerationHandler(moderationUsecase)
+	ratingHandler := handler.NewRatingHandler(ratingUsecase)
+	userListHandler := handler.NewUserListHandler(userListUsecase)
+	collectionHandler := handler.NewCollectionHandler(collectionUsecase)
+	statsHandler := handler.NewStatsHandler(statsUsecase)
+	personHandler := handler.NewPersonHandler(personUsecase)
+	referenceHandler := handler.NewReferenceHandler(referenceUsecase)
+
+	// Health checks.
+	drain := &health.Drain{}
+	healthRegistry := health.NewRegistry(health.DefaultTimeout)
+	healthRegistry.Register(
+		mongodb.PingChecker(client),
+		mongodb.IndexChecker(db),
+		normalized,
+		drain,
+	)
+	healthHandler := handler.NewHealthHandler(healthRegistry)
 
 	// Router.
-	router := gin.Default()
-	routes.SetupRoutes(router, movieHandler, commentHandler)
+	serviceName := cfg.Tracing.ServiceName
+	if serviceName == "" {
+		serviceName = tracing.DefaultServiceName
+	}
+
+	trustedProxies, err := middleware.ParseTrustedProxies(cfg.HTTP.TrustedProxies)
+	if err != nil {
+		return err
+	}
+
+	router := gin.New()
+	// Only the configured proxies may set X-Forwarded-For, otherwise
+	// clients could pick the IP address they are rate limited by.
+	if err := router.SetTrustedProxies(cfg.HTTP.TrustedProxies); err != nil {
+		return fmt.Errorf("trusted proxies: %w", err)
+	}
+	router.Use(
+		middleware.RequestID(),
+		middleware.Logger(logger),
+		middleware.Recovery(),
+		middleware.BodyLimit(cfg.HTTP.MaxBodyBytes),
+		otelgin.Middleware(serviceName),
+		middleware.Metrics(appMetrics),
+	)
+	routes.SetupRoutes(
+		router,
+		movieHandler,
+		commentHandler,
+		moderationHandler,
+		ratingHandler,
+		userListHandler,
+		collectionHandler,
+		statsHandler,
+		personHandler,
+		referenceHandler,
+		healthHandler,
+		appMetrics.Handler(),
+		middleware.RateLimit(limiter, trustedProxies),
+	)
+
+	listener, err := net.Listen("tcp", ":"+cfg.Port)
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+
+	shutdownTimeout := cfg.HTTP.ShutdownTimeout
+	if shutdownTimeout == 0 {
+		shutdownTimeout = defaultShutdownTimeout
+	}
+
+	srv := &http.Server{
+		Handler:           router,
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
+		MaxHeaderBytes:    cfg.HTTP.MaxHeaderBytes,
+	}
+
+	return serve(ctx, srv, listener, drain, cfg.HTTP.DrainDelay, shutdownTimeout)
+}
+
+// serve runs srv on listener until ctx is done. It then starts drain, so
+// readiness checks fail, and keeps serving for drainDelay while load
+// balancers stop sending it traffic. Finally it stops accepting connections
+// and waits up to shutdownTimeout for in-flight requests to finish before
+// closing them.
+func serve(ctx context.Context, srv *http.Server, listener net.Listener, drain *health.Drain, drainDelay, shutdownTimeout time.Duration) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("serve: %w", err)
+	case <-ctx.Done():
+	}
 
-	return router.Run(":" + cfg.Port)
+	drain.Start()
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("serve: %w", err)
+	case <-time.After(drainDelay):
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		srv.Close()
+		return fmt.Errorf("shutdown: %w", err)
+	}
+
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serve: %w", err)
+	}
+
+	return nil
 }
 
-func initializeMongoDB(ctx context.Context, uri string) (*mongo.Client, error) {
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+// workers runs background tasks until they are stopped.
+type workers struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// newWorkers returns workers whose tasks see the values of parent, such as
+// its logger, but are only cancelled by Stop.
+func newWorkers(parent context.Context) *workers {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(parent))
+	return &workers{ctx: ctx, cancel: cancel}
+}
+
+// Go runs task in the background. Its context is cancelled by Stop.
+func (w *workers) Go(task func(ctx context.Context)) {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		task(w.ctx)
+	}()
+}
+
+// Stop cancels every task and waits for them to return.
+func (w *workers) Stop() {
+	w.cancel()
+	w.wg.Wait()
+}
+
+func initializeMongoDB(ctx context.Context, opts *options.ClientOptions, monitor *event.CommandMonitor, poolMonitor *event.PoolMonitor) (*mongo.Client, error) {
+	opts.SetMonitor(monitor).SetPoolMonitor(poolMonitor)
+
+	client, err := mongo.Connect(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -64,3 +383,127 @@ func initializeMongoDB(ctx context.Context, uri string) (*mongo.Client, error) {
 
 	return client, nil
 }
+
+// mongoClientOptions builds the client options for cfg. Only settings that
+// are configured are applied, leaving the rest to the URI and the driver.
+func mongoClientOptions(cfg config.MongoDB) (*options.ClientOptions, error) {
+	opts := options.Client().ApplyURI(cfg.URI)
+
+	if cfg.OperationTimeout > 0 {
+		opts.SetTimeout(cfg.OperationTimeout)
+	}
+	if cfg.ConnectTimeout > 0 {
+		opts.SetConnectTimeout(cfg.ConnectTimeout)
+	}
+	if cfg.ServerSelectionTimeout > 0 {
+		opts.SetServerSelectionTimeout(cfg.ServerSelectionTimeout)
+	}
+	if cfg.MinPoolSize > 0 {
+		opts.SetMinPoolSize(cfg.MinPoolSize)
+	}
+	if cfg.MaxPoolSize > 0 {
+		opts.SetMaxPoolSize(cfg.MaxPoolSize)
+	}
+	if cfg.MaxConnIdleTime > 0 {
+		opts.SetMaxConnIdleTime(cfg.MaxConnIdleTime)
+	}
+	if cfg.ReadPreference != "" {
+		mode, err := readpref.ModeFromString(cfg.ReadPreference)
+		if err != nil {
+			return nil, err
+		}
+		rp, err := readpref.New(mode)
+		if err != nil {
+			return nil, err
+		}
+		opts.SetReadPreference(rp)
+	}
+	if cfg.ReadConcern != "" {
+		opts.SetReadConcern(&readconcern.ReadConcern{Level: cfg.ReadConcern})
+	}
+	if cfg.WriteConcern != "" || cfg.WriteJournal != nil {
+		wc := &writeconcern.WriteConcern{Journal: cfg.WriteJournal}
+		if cfg.WriteConcern == config.WriteConcernMajority {
+			wc.W = config.WriteConcernMajority
+		} else if cfg.WriteConcern != "" {
+			w, err := strconv.Atoi(cfg.WriteConcern)
+			if err != nil {
+				return nil, fmt.Errorf("write concern: %w", err)
+			}
+			wc.W = w
+		}
+		opts.SetWriteConcern(wc)
+	}
+	if cfg.RetryWrites != nil {
+		opts.SetRetryWrites(*cfg.RetryWrites)
+	}
+	if cfg.RetryReads != nil {
+		opts.SetRetryReads(*cfg.RetryReads)
+	}
+
+	return opts, opts.Validate()
+}
+
+// combineCommandMonitors returns a monitor passing every event to each of
+// monitors, as the driver only accepts one.
+func combineCommandMonitors(monitors ...*event.CommandMonitor) *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			for _, m := range monitors {
+				if m.Started != nil {
+					m.Started(ctx, e)
+				}
+			}
+		},
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			for _, m := range monitors {
+				if m.Succeeded != nil {
+					m.Succeeded(ctx, e)
+				}
+			}
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			for _, m := range monitors {
+				if m.Failed != nil {
+					m.Failed(ctx, e)
+				}
+			}
+		},
+	}
+}
+
+// newContentFilter builds the chain of filters enabled in cfg. Filters with
+// no action configured reject matching comments.
+func newContentFilter(cfg config.ContentFilter, commentRepo domain.CommentRepository) (service.ContentFilter, error) {
+	action := func(name string) (service.FilterAction, error) {
+		if name == "" {
+			return service.FilterReject, nil
+		}
+		return service.ParseFilterAction(name)
+	}
+
+	var chain service.FilterChain
+	if len(cfg.BlockedWords) > 0 {
+		a, err := action(cfg.BlockedWordsAction)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, service.NewWordListFilter(cfg.BlockedWords, a))
+	}
+	if cfg.MaxLinks > 0 {
+		a, err := action(cfg.LinksAction)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, service.NewLinkLimitFilter(cfg.MaxLinks, a))
+	}
+	if cfg.DuplicateWindow > 0 {
+		a, err := action(cfg.DuplicateAction)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, service.NewDuplicateFilter(commentRepo, cfg.DuplicateWindow, a))
+	}
+
+	return chain, nil
+}
@@ -0,0 +1,262 @@
+package runner
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yasv98/movies-api/internal/config"
+	"github.com/yasv98/movies-api/internal/health"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+func TestServe_DrainsInFlightRequests(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "done")
+	})}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	url := "http://" + listener.Addr().String()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(ctx, srv, listener, &health.Drain{}, 0, 5*time.Second)
+	}()
+
+	type result struct {
+		body string
+		err  error
+	}
+	responses := make(chan result, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			responses <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		responses <- result{body: string(body), err: err}
+	}()
+
+	<-started
+	cancel()
+
+	select {
+	case err := <-served:
+		t.Fatalf("serve returned before the request finished: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// New connections are refused once shutdown has begun.
+	_, err = net.DialTimeout("tcp", listener.Addr().String(), time.Second)
+	assert.Error(t, err)
+
+	close(release)
+
+	res := <-responses
+	require.NoError(t, res.err)
+	assert.Equal(t, "done", res.body)
+
+	select {
+	case err := <-served:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve did not return after the request finished")
+	}
+}
+
+func TestServe_DrainDelay(t *testing.T) {
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	})}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	url := "http://" + listener.Addr().String()
+
+	drain := &health.Drain{}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(ctx, srv, listener, drain, 300*time.Millisecond, 5*time.Second)
+	}()
+
+	require.NoError(t, drain.Check(context.Background()))
+	cancel()
+
+	// Readiness fails straight away, while new requests are still served
+	// until the delay has passed.
+	require.Eventually(t, func() bool {
+		return drain.Check(context.Background()) == health.ErrDraining
+	}, time.Second, 5*time.Millisecond)
+
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	resp, err := client.Get(url)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	select {
+	case err := <-served:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve did not return after the drain delay")
+	}
+
+	_, err = net.DialTimeout("tcp", listener.Addr().String(), time.Second)
+	assert.Error(t, err)
+}
+
+func TestServe_ShutdownTimeout(t *testing.T) {
+	started := make(chan struct{})
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+	})}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(ctx, srv, listener, &health.Drain{}, 0, 50*time.Millisecond)
+	}()
+
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String())
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+
+	<-started
+	cancel()
+
+	select {
+	case err := <-served:
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve did not give up on the stuck request")
+	}
+}
+
+func TestServe_ListenerError(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	require.NoError(t, listener.Close())
+
+	err = serve(context.Background(), &http.Server{}, listener, &health.Drain{}, 0, time.Second)
+	assert.Error(t, err)
+}
+
+func TestWorkers_Stop(t *testing.T) {
+	w := newWorkers(context.Background())
+
+	stopped := make(chan struct{})
+	w.Go(func(ctx context.Context) {
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		close(stopped)
+	})
+	w.Go(func(ctx context.Context) {})
+
+	w.Stop()
+
+	select {
+	case <-stopped:
+	default:
+		t.Fatal("Stop returned before the worker finished")
+	}
+}
+
+func TestCombineCommandMonitors(t *testing.T) {
+	var calls []string
+	first := &event.CommandMonitor{
+		Started:   func(context.Context, *event.CommandStartedEvent) { calls = append(calls, "first started") },
+		Succeeded: func(context.Context, *event.CommandSucceededEvent) { calls = append(calls, "first succeeded") },
+	}
+	second := &event.CommandMonitor{
+		Started: func(context.Context, *event.CommandStartedEvent) { calls = append(calls, "second started") },
+		Failed:  func(context.Context, *event.CommandFailedEvent) { calls = append(calls, "second failed") },
+	}
+
+	monitor := combineCommandMonitors(first, second)
+	ctx := context.Background()
+	monitor.Started(ctx, &event.CommandStartedEvent{})
+	monitor.Succeeded(ctx, &event.CommandSucceededEvent{})
+	monitor.Failed(ctx, &event.CommandFailedEvent{})
+
+	assert.Equal(t, []string{
+		"first started",
+		"second started",
+		"first succeeded",
+		"second failed",
+	}, calls)
+}
+
+func TestMongoClientOptions(t *testing.T) {
+	journal := true
+	retryWrites := false
+
+	opts, err := mongoClientOptions(config.MongoDB{
+		URI:                    "mongodb://localhost:27017/?maxPoolSize=20",
+		OperationTimeout:       5 * time.Second,
+		ServerSelectionTimeout: 2 * time.Second,
+		MinPoolSize:            2,
+		ReadPreference:         "secondaryPreferred",
+		ReadConcern:            "majority",
+		WriteConcern:           "2",
+		WriteJournal:           &journal,
+		RetryWrites:            &retryWrites,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 5*time.Second, *opts.Timeout)
+	assert.Equal(t, 2*time.Second, *opts.ServerSelectionTimeout)
+	assert.Equal(t, uint64(2), *opts.MinPoolSize)
+	// Unset options are left to the URI.
+	assert.Equal(t, uint64(20), *opts.MaxPoolSize)
+	assert.Nil(t, opts.ConnectTimeout)
+	assert.Equal(t, readpref.SecondaryPreferredMode, opts.ReadPreference.Mode())
+	assert.Equal(t, "majority", opts.ReadConcern.Level)
+	assert.Equal(t, 2, opts.WriteConcern.W)
+	assert.True(t, *opts.WriteConcern.Journal)
+	assert.False(t, *opts.RetryWrites)
+	assert.Nil(t, opts.RetryReads)
+}
+
+func TestMongoClientOptions_URITimeouts(t *testing.T) {
+	cfg := config.Defaults()
+	cfg.MonogoDB.URI = "mongodb://localhost:27017/?serverSelectionTimeoutMS=1500&connectTimeoutMS=2500&timeoutMS=3500"
+
+	opts, err := mongoClientOptions(cfg.MonogoDB)
+	require.NoError(t, err)
+
+	// Timeouts the config leaves unset keep the values from the URI.
+	assert.Equal(t, 1500*time.Millisecond, *opts.ServerSelectionTimeout)
+	assert.Equal(t, 2500*time.Millisecond, *opts.ConnectTimeout)
+	assert.Equal(t, 3500*time.Millisecond, *opts.Timeout)
+}
+
+func TestMongoClientOptions_Majority(t *testing.T) {
+	opts, err := mongoClientOptions(config.MongoDB{
+		URI:          "mongodb://localhost:27017",
+		WriteConcern: config.WriteConcernMajority,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "majority", opts.WriteConcern.W)
+}
@@ -3,38 +3,176 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/go-playground/validator"
-	"gopkg.in/yaml.v3"
 )
 
+// WriteConcernMajority waits for writes to reach a majority of members.
+const WriteConcernMajority = "majority"
+
 type (
 	Config struct {
-		Port     string  `yaml:"port" validate:"required"`
-		MonogoDB MongoDB `yaml:"mongodb" validate:"required"`
+		Port          string        `yaml:"port" validate:"required"`
+		MonogoDB      MongoDB       `yaml:"mongodb" validate:"required"`
+		HTTP          HTTP          `yaml:"http"`
+		Moderation    Moderation    `yaml:"moderation"`
+		ContentFilter ContentFilter `yaml:"content_filter"`
+		Reference     Reference     `yaml:"reference"`
+		Tracing       Tracing       `yaml:"tracing"`
+		Logging       Logging       `yaml:"logging"`
+		RateLimit     RateLimit     `yaml:"rate_limit"`
+		Idempotency   Idempotency   `yaml:"idempotency"`
 	}
 
+	// MongoDB configures the client. Options left unset fall back to those
+	// in the URI and then to the driver's defaults.
 	MongoDB struct {
 		URI      string `yaml:"uri" validate:"required"`
 		Database string `yaml:"database" validate:"required"`
+		// OperationTimeout bounds each operation that has no deadline of its
+		// own. Zero leaves it to the URI's timeoutMS, if any.
+		OperationTimeout       time.Duration `yaml:"operation_timeout" validate:"min=0"`
+		ConnectTimeout         time.Duration `yaml:"connect_timeout" validate:"min=0"`
+		ServerSelectionTimeout time.Duration `yaml:"server_selection_timeout" validate:"min=0"`
+		MinPoolSize            uint64        `yaml:"min_pool_size"`
+		MaxPoolSize            uint64        `yaml:"max_pool_size" validate:"omitempty,gtefield=MinPoolSize"`
+		MaxConnIdleTime        time.Duration `yaml:"max_conn_idle_time" validate:"min=0"`
+		ReadPreference         string        `yaml:"read_preference" validate:"omitempty,oneof=primary primaryPreferred secondary secondaryPreferred nearest"`
+		ReadConcern            string        `yaml:"read_concern" validate:"omitempty,oneof=local available majority linearizable snapshot"`
+		// WriteConcern is majority or the number of members that must
+		// acknowledge a write.
+		WriteConcern string `yaml:"write_concern" validate:"omitempty,write_concern"`
+		WriteJournal *bool  `yaml:"write_journal"`
+		RetryWrites  *bool  `yaml:"retry_writes"`
+		RetryReads   *bool  `yaml:"retry_reads"`
+	}
+
+	// HTTP configures the server. Zero timeouts and sizes are unlimited,
+	// apart from MaxHeaderBytes which falls back to net/http's default.
+	HTTP struct {
+		// DrainDelay is how long the server keeps accepting requests while
+		// reporting itself not ready when stopped, so load balancers stop
+		// routing to it before it closes its listener.
+		DrainDelay time.Duration `yaml:"drain_delay" validate:"min=0"`
+		// ShutdownTimeout is how long in-flight requests are given to finish
+		// when the server is stopped.
+		ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" validate:"min=0"`
+		ReadTimeout       time.Duration `yaml:"read_timeout" validate:"min=0"`
+		ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" validate:"min=0"`
+		WriteTimeout      time.Duration `yaml:"write_timeout" validate:"min=0"`
+		IdleTimeout       time.Duration `yaml:"idle_timeout" validate:"min=0"`
+		MaxHeaderBytes    int           `yaml:"max_header_bytes" validate:"min=0"`
+		// MaxBodyBytes is the largest request body accepted.
+		MaxBodyBytes int64 `yaml:"max_body_bytes" validate:"min=0"`
+		// TrustedProxies lists the addresses or CIDR ranges of the gateway
+		// and proxies in front of the API. Only they are trusted to set
+		// X-Forwarded-For, and X-User-ID for rate limiting.
+		TrustedProxies []string `yaml:"trusted_proxies" validate:"dive,ip|cidr"`
+	}
+
+	Tracing struct {
+		Enabled bool `yaml:"enabled"`
+		// Exporter is where spans are sent, otlp for an OTLP/HTTP collector
+		// or stdout for local use.
+		Exporter string `yaml:"exporter" validate:"omitempty,oneof=otlp stdout"`
+		// Endpoint is the host and port of the OTLP collector.
+		Endpoint    string `yaml:"endpoint"`
+		Insecure    bool   `yaml:"insecure"`
+		ServiceName string `yaml:"service_name"`
+		// SampleRatio is the fraction of new traces recorded, every trace is
+		// recorded when unset.
+		SampleRatio *float64 `yaml:"sample_ratio" validate:"omitempty,min=0,max=1"`
+	}
+
+	Logging struct {
+		Level string `yaml:"level" validate:"omitempty,oneof=debug info warn error"`
+		// Format is json for log collectors or text for reading locally.
+		Format string `yaml:"format" validate:"omitempty,oneof=json text"`
+	}
+
+	// RateLimit limits how often each client, identified by API key, user or
+	// IP address, may call the API. Reads are GET, HEAD and OPTIONS
+	// requests, everything else is a write.
+	RateLimit struct {
+		Enabled bool          `yaml:"enabled"`
+		Read    RateLimitRule `yaml:"read"`
+		Write   RateLimitRule `yaml:"write"`
+		// APIKeys lists the keys accepted in the X-API-Key header. Requests
+		// with any other key are limited by IP address.
+		APIKeys []string `yaml:"api_keys"`
+	}
+
+	// RateLimitRule allows Requests per Period on average with bursts of up
+	// to Burst, which defaults to Requests. Zero requests is unlimited.
+	RateLimitRule struct {
+		Requests int           `yaml:"requests" validate:"min=0"`
+		Period   time.Duration `yaml:"period" validate:"required_with=Requests,min=0"`
+		Burst    int           `yaml:"burst" validate:"min=0"`
+	}
+
+	Idempotency struct {
+		// TTL is how long responses to requests with an Idempotency-Key
+		// are kept for retries.
+		TTL time.Duration `yaml:"ttl" validate:"min=0"`
+	}
+
+	Moderation struct {
+		// FlagThreshold is the number of flags that hides a comment until a
+		// moderator reviews it.
+		FlagThreshold int `yaml:"flag_threshold" validate:"omitempty,min=1"`
+		// Moderators lists the user IDs allowed to review flagged comments.
+		Moderators []string `yaml:"moderators"`
+	}
+
+	// ContentFilter configures the checks new and edited comments go
+	// through. Each action is one of reject, hold or allow.
+	ContentFilter struct {
+		BlockedWords       []string `yaml:"blocked_words"`
+		BlockedWordsAction string   `yaml:"blocked_words_action" validate:"omitempty,oneof=reject hold allow"`
+		// MaxLinks is the number of links a comment may contain, zero
+		// disables the check.
+		MaxLinks    int    `yaml:"max_links" validate:"min=0"`
+		LinksAction string `yaml:"links_action" validate:"omitempty,oneof=reject hold allow"`
+		// DuplicateWindow is how long the same text from the same email is
+		// treated as a duplicate, zero disables the check.
+		DuplicateWindow time.Duration `yaml:"duplicate_window" validate:"min=0"`
+		DuplicateAction string        `yaml:"duplicate_action" validate:"omitempty,oneof=reject hold allow"`
+	}
+
+	Reference struct {
+		// RefreshInterval is how often the cached genres, countries,
+		// languages and ratings are reloaded.
+		RefreshInterval time.Duration `yaml:"refresh_interval" validate:"min=0"`
 	}
 )
 
+// LoadConfig loads the config file at configPath over the defaults,
+// applying overrides from the process environment.
 func LoadConfig(configPath string) (*Config, error) {
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return nil, fmt.Errorf("reading config file: %w", err)
+	return Load(Options{Path: configPath, Environ: os.Environ()})
+}
+
+func validate(cfg *Config) error {
+	v := validator.New()
+	if err := v.RegisterValidation("write_concern", validWriteConcern); err != nil {
+		return err
 	}
 
-	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("parsing yaml: %w", err)
+	if err := v.Struct(cfg); err != nil {
+		return fmt.Errorf("validating config: %w", err)
 	}
+	return nil
+}
 
-	validate := validator.New()
-	if err := validate.Struct(&cfg); err != nil {
-		return nil, fmt.Errorf("validating config: %w", err)
+// validWriteConcern accepts majority or a non-negative number of members.
+func validWriteConcern(fl validator.FieldLevel) bool {
+	w := fl.Field().String()
+	if w == WriteConcernMajority {
+		return true
 	}
 
-	return &cfg, nil
+	n, err := strconv.Atoi(w)
+	return err == nil && n >= 0
 }
@@ -1,9 +1,13 @@
 package config
 
 import (
+	"flag"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -30,10 +34,311 @@ mongodb:
 				},
 			},
 		},
-		"Missing required field": {
+		"Valid config with moderation": {
+			configYAML: `
+port: "8080"
+mongodb:
+  uri: "mongodb://localhost:27017"
+  database: "testdb"
+moderation:
+  flag_threshold: 5
+  moderators: ["alice", "bob"]`,
+			assertError: assert.NoError,
+			expected: &Config{
+				Port: "8080",
+				MonogoDB: MongoDB{
+					URI:      "mongodb://localhost:27017",
+					Database: "testdb",
+				},
+				Moderation: Moderation{
+					FlagThreshold: 5,
+					Moderators:    []string{"alice", "bob"},
+				},
+			},
+		},
+		"Invalid flag threshold": {
+			configYAML: `
+port: "8080"
+mongodb:
+  uri: "mongodb://localhost:27017"
+  database: "testdb"
+moderation:
+  flag_threshold: -1`,
+			assertError: assert.Error,
+		},
+		"Valid config with content filter": {
+			configYAML: `
+port: "8080"
+mongodb:
+  uri: "mongodb://localhost:27017"
+  database: "testdb"
+content_filter:
+  blocked_words: ["spam"]
+  blocked_words_action: reject
+  max_links: 2
+  links_action: hold
+  duplicate_window: 10m
+  duplicate_action: reject`,
+			assertError: assert.NoError,
+			expected: &Config{
+				Port: "8080",
+				MonogoDB: MongoDB{
+					URI:      "mongodb://localhost:27017",
+					Database: "testdb",
+				},
+				ContentFilter: ContentFilter{
+					BlockedWords:       []string{"spam"},
+					BlockedWordsAction: "reject",
+					MaxLinks:           2,
+					LinksAction:        "hold",
+					DuplicateWindow:    10 * time.Minute,
+					DuplicateAction:    "reject",
+				},
+			},
+		},
+		"Invalid content filter action": {
+			configYAML: `
+port: "8080"
+mongodb:
+  uri: "mongodb://localhost:27017"
+  database: "testdb"
+content_filter:
+  links_action: delete`,
+			assertError: assert.Error,
+		},
+		"Valid config with shutdown timeout": {
+			configYAML: `
+port: "8080"
+mongodb:
+  uri: "mongodb://localhost:27017"
+  database: "testdb"
+http:
+  shutdown_timeout: 30s`,
+			assertError: assert.NoError,
+			expected: &Config{
+				Port: "8080",
+				MonogoDB: MongoDB{
+					URI:      "mongodb://localhost:27017",
+					Database: "testdb",
+				},
+				HTTP: HTTP{
+					ShutdownTimeout: 30 * time.Second,
+				},
+			},
+		},
+		"Invalid shutdown timeout": {
+			configYAML: `
+port: "8080"
+mongodb:
+  uri: "mongodb://localhost:27017"
+  database: "testdb"
+http:
+  shutdown_timeout: -1s`,
+			assertError: assert.Error,
+		},
+		"Valid config with tracing": {
 			configYAML: `
+port: "8080"
+mongodb:
+  uri: "mongodb://localhost:27017"
+  database: "testdb"
+tracing:
+  enabled: true
+  exporter: otlp
+  endpoint: collector:4318
+  sample_ratio: 0.25`,
+			assertError: assert.NoError,
+			expected: &Config{
+				Port: "8080",
+				MonogoDB: MongoDB{
+					URI:      "mongodb://localhost:27017",
+					Database: "testdb",
+				},
+				Tracing: Tracing{
+					Enabled:     true,
+					Exporter:    "otlp",
+					Endpoint:    "collector:4318",
+					SampleRatio: func() *float64 { r := 0.25; return &r }(),
+				},
+			},
+		},
+		"Invalid tracing sample ratio": {
+			configYAML: `
+port: "8080"
 mongodb:
   uri: "mongodb://localhost:27017"
+  database: "testdb"
+tracing:
+  sample_ratio: 1.5`,
+			assertError: assert.Error,
+		},
+		"Valid config with reference refresh interval": {
+			configYAML: `
+port: "8080"
+mongodb:
+  uri: "mongodb://localhost:27017"
+  database: "testdb"
+reference:
+  refresh_interval: 1h`,
+			assertError: assert.NoError,
+			expected: &Config{
+				Port: "8080",
+				MonogoDB: MongoDB{
+					URI:      "mongodb://localhost:27017",
+					Database: "testdb",
+				},
+				Reference: Reference{
+					RefreshInterval: time.Hour,
+				},
+			},
+		},
+		"Valid config with logging": {
+			configYAML: `
+port: "8080"
+mongodb:
+  uri: "mongodb://localhost:27017"
+  database: "testdb"
+logging:
+  level: debug
+  format: text`,
+			assertError: assert.NoError,
+			expected: &Config{
+				Port: "8080",
+				MonogoDB: MongoDB{
+					URI:      "mongodb://localhost:27017",
+					Database: "testdb",
+				},
+				Logging: Logging{
+					Level:  "debug",
+					Format: "text",
+				},
+			},
+		},
+		"Invalid log format": {
+			configYAML: `
+port: "8080"
+mongodb:
+  uri: "mongodb://localhost:27017"
+  database: "testdb"
+logging:
+  format: xml`,
+			assertError: assert.Error,
+		},
+		"Valid config with server and mongo tuning": {
+			configYAML: `
+port: "8080"
+mongodb:
+  uri: "mongodb://localhost:27017"
+  database: "testdb"
+  operation_timeout: 5s
+  min_pool_size: 5
+  max_pool_size: 50
+  read_preference: secondaryPreferred
+  read_concern: majority
+  write_concern: "2"
+  retry_writes: false
+http:
+  read_timeout: 5s
+  max_body_bytes: 2048`,
+			assertError: assert.NoError,
+			expected: &Config{
+				Port: "8080",
+				MonogoDB: MongoDB{
+					URI:              "mongodb://localhost:27017",
+					Database:         "testdb",
+					OperationTimeout: 5 * time.Second,
+					MinPoolSize:      5,
+					MaxPoolSize:      50,
+					ReadPreference:   "secondaryPreferred",
+					ReadConcern:      "majority",
+					WriteConcern:     "2",
+					RetryWrites:      func() *bool { b := false; return &b }(),
+				},
+				HTTP: HTTP{
+					ReadTimeout:  5 * time.Second,
+					MaxBodyBytes: 2048,
+				},
+			},
+		},
+		"Invalid pool sizes": {
+			configYAML: `
+port: "8080"
+mongodb:
+  uri: "mongodb://localhost:27017"
+  database: "testdb"
+  min_pool_size: 10
+  max_pool_size: 5`,
+			assertError: assert.Error,
+		},
+		"Invalid read preference": {
+			configYAML: `
+port: "8080"
+mongodb:
+  uri: "mongodb://localhost:27017"
+  database: "testdb"
+  read_preference: fastest`,
+			assertError: assert.Error,
+		},
+		"Invalid write concern": {
+			configYAML: `
+port: "8080"
+mongodb:
+  uri: "mongodb://localhost:27017"
+  database: "testdb"
+  write_concern: most`,
+			assertError: assert.Error,
+		},
+		"Negative timeout": {
+			configYAML: `
+port: "8080"
+mongodb:
+  uri: "mongodb://localhost:27017"
+  database: "testdb"
+http:
+  write_timeout: -1s`,
+			assertError: assert.Error,
+		},
+		"Valid config with rate limit": {
+			configYAML: `
+port: "8080"
+mongodb:
+  uri: "mongodb://localhost:27017"
+  database: "testdb"
+rate_limit:
+  enabled: true
+  write:
+    requests: 5
+    period: 1s
+    burst: 1`,
+			assertError: assert.NoError,
+			expected: &Config{
+				Port: "8080",
+				MonogoDB: MongoDB{
+					URI:      "mongodb://localhost:27017",
+					Database: "testdb",
+				},
+				RateLimit: RateLimit{
+					Enabled: true,
+					Write:   RateLimitRule{Requests: 5, Period: time.Second, Burst: 1},
+				},
+			},
+		},
+		"Rate limit without period": {
+			configYAML: `
+port: "8080"
+mongodb:
+  uri: "mongodb://localhost:27017"
+  database: "testdb"
+rate_limit:
+  read:
+    requests: 5
+    period: 0s`,
+			assertError: assert.Error,
+		},
+		"Missing required field": {
+			configYAML: `
+port: "8080"
+mongodb:
   database: "testdb"`,
 			assertError: assert.Error,
 		},
@@ -55,14 +360,221 @@ mongodb: {
 			err := os.WriteFile(configPath, []byte(tt.configYAML), 0644)
 			require.NoError(t, err)
 
-			got, err := LoadConfig(configPath)
+			got, err := Load(Options{Path: configPath})
 			tt.assertError(t, err)
-			assert.Equal(t, tt.expected, got)
+			assert.Equal(t, withDefaults(tt.expected), got)
 		})
 	}
 }
 
+func TestLoad_Layers(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+port: "9090"
+mongodb:
+  uri: "mongodb://file:27017"
+  database: "filedb"
+logging:
+  level: warn`), 0644))
+
+	secretPath := filepath.Join(tmpDir, "uri")
+	require.NoError(t, os.WriteFile(secretPath, []byte("mongodb://secret:27017\n"), 0600))
+
+	tests := map[string]struct {
+		opts        Options
+		assertError assert.ErrorAssertionFunc
+		assert      func(t *testing.T, cfg *Config)
+	}{
+		"Defaults only": {
+			opts: Options{Environ: []string{
+				"MOVIES_API_MONGODB_URI=mongodb://env:27017",
+				"MOVIES_API_MONGODB_DATABASE=envdb",
+			}},
+			assertError: assert.NoError,
+			assert: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, "8080", cfg.Port)
+				assert.Equal(t, "info", cfg.Logging.Level)
+				assert.Equal(t, "mongodb://env:27017", cfg.MonogoDB.URI)
+			},
+		},
+		"File over defaults": {
+			opts:        Options{Path: configPath},
+			assertError: assert.NoError,
+			assert: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, "9090", cfg.Port)
+				assert.Equal(t, "warn", cfg.Logging.Level)
+				assert.Equal(t, "json", cfg.Logging.Format)
+			},
+		},
+		"Environment over file": {
+			opts: Options{
+				Path: configPath,
+				Environ: []string{
+					"HOME=/root",
+					"MOVIES_API_PORT=7070",
+					"MOVIES_API_HTTP_SHUTDOWN_TIMEOUT=30s",
+					"MOVIES_API_MODERATION_MODERATORS=alice, bob",
+					"MOVIES_API_TRACING_ENABLED=true",
+					"MOVIES_API_TRACING_SAMPLE_RATIO=0.5",
+				},
+			},
+			assertError: assert.NoError,
+			assert: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, "7070", cfg.Port)
+				assert.Equal(t, 30*time.Second, cfg.HTTP.ShutdownTimeout)
+				assert.Equal(t, []string{"alice", "bob"}, cfg.Moderation.Moderators)
+				assert.True(t, cfg.Tracing.Enabled)
+				require.NotNil(t, cfg.Tracing.SampleRatio)
+				assert.Equal(t, 0.5, *cfg.Tracing.SampleRatio)
+				assert.Equal(t, "mongodb://file:27017", cfg.MonogoDB.URI)
+			},
+		},
+		"Flags over environment": {
+			opts: Options{
+				Path:      configPath,
+				Environ:   []string{"MOVIES_API_PORT=7070"},
+				Overrides: Overrides{"port": "6060"},
+			},
+			assertError: assert.NoError,
+			assert: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, "6060", cfg.Port)
+			},
+		},
+		"Secret from file": {
+			opts: Options{
+				Path:    configPath,
+				Environ: []string{"MOVIES_API_MONGODB_URI_FILE=" + secretPath},
+			},
+			assertError: assert.NoError,
+			assert: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, "mongodb://secret:27017", cfg.MonogoDB.URI)
+			},
+		},
+		"Secret and value both set": {
+			opts: Options{
+				Path: configPath,
+				Environ: []string{
+					"MOVIES_API_MONGODB_URI=mongodb://env:27017",
+					"MOVIES_API_MONGODB_URI_FILE=" + secretPath,
+				},
+			},
+			assertError: assert.Error,
+		},
+		"Missing secret file": {
+			opts: Options{
+				Path:    configPath,
+				Environ: []string{"MOVIES_API_MONGODB_URI_FILE=" + filepath.Join(tmpDir, "missing")},
+			},
+			assertError: assert.Error,
+		},
+		"Unknown environment variable": {
+			opts: Options{
+				Path:    configPath,
+				Environ: []string{"MOVIES_API_MONGO_URI=mongodb://env:27017"},
+				Logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+			},
+			assertError: assert.NoError,
+			assert: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, "mongodb://file:27017", cfg.MonogoDB.URI)
+			},
+		},
+		"Kubernetes service links": {
+			opts: Options{
+				Path: configPath,
+				Environ: []string{
+					"MOVIES_API_SERVICE_HOST=10.0.0.1",
+					"MOVIES_API_SERVICE_PORT=8080",
+					"MOVIES_API_PORT=tcp://10.0.0.1:8080",
+					"MOVIES_API_PORT_8080_TCP=tcp://10.0.0.1:8080",
+					"MOVIES_API_PORT_8080_TCP_PROTO=tcp",
+					"MOVIES_API_PORT_8080_TCP_PORT=8080",
+					"MOVIES_API_PORT_8080_TCP_ADDR=10.0.0.1",
+				},
+				Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+			},
+			assertError: assert.NoError,
+			assert: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, "9090", cfg.Port)
+			},
+		},
+		"Unknown flag key": {
+			opts: Options{
+				Path:      configPath,
+				Overrides: Overrides{"mongo.uri": "mongodb://flag:27017"},
+			},
+			assertError: assert.Error,
+		},
+		"Unparsable value": {
+			opts: Options{
+				Path:    configPath,
+				Environ: []string{"MOVIES_API_HTTP_SHUTDOWN_TIMEOUT=soon"},
+			},
+			assertError: assert.Error,
+		},
+		"Override fails validation": {
+			opts: Options{
+				Path:    configPath,
+				Environ: []string{"MOVIES_API_LOGGING_FORMAT=xml"},
+			},
+			assertError: assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := Load(tt.opts)
+			tt.assertError(t, err)
+			if tt.assert != nil {
+				require.NotNil(t, got)
+				tt.assert(t, got)
+			}
+		})
+	}
+}
+
+func TestBindFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	overrides := BindFlags(fs)
+
+	require.NoError(t, fs.Parse([]string{
+		"-mongodb.uri", "mongodb://flag:27017",
+		"-tracing.enabled",
+		"-content_filter.max_links=5",
+	}))
+
+	assert.Equal(t, Overrides{
+		"mongodb.uri":              "mongodb://flag:27017",
+		"tracing.enabled":          "true",
+		"content_filter.max_links": "5",
+	}, overrides)
+}
+
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, "MOVIES_API_MONGODB_URI", EnvName("mongodb.uri"))
+	assert.Equal(t, "MOVIES_API_CONTENT_FILTER_MAX_LINKS", EnvName("content_filter.max_links"))
+}
+
 func TestLoadConfig_FileNotFound(t *testing.T) {
 	_, err := LoadConfig("nonexistent.yaml")
 	assert.Error(t, err)
 }
+
+// withDefaults fills in the fields of expected left unset by a test case
+// with their defaults.
+func withDefaults(expected *Config) *Config {
+	if expected == nil {
+		return nil
+	}
+
+	defaults := Defaults()
+	cfg := *expected
+	defaultFields := configFields(&defaults)
+	for key, field := range configFields(&cfg) {
+		if field.IsZero() {
+			field.Set(defaultFields[key])
+		}
+	}
+
+	return &cfg
+}
@@ -0,0 +1,223 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadableKeys lists the keys, or key prefixes ending in a dot, that can
+// change without a restart. Changes to any other key are logged and ignored
+// until the service is restarted.
+var reloadableKeys = []string{
+	"logging.level",
+	"moderation.",
+	"content_filter.",
+	"rate_limit.",
+}
+
+// configMapDataDir is the symlink Kubernetes points at a new directory when
+// a ConfigMap mounted as a volume changes.
+const configMapDataDir = "..data"
+
+// DefaultReloadDebounce is how long the watcher waits for a burst of file
+// events to settle before reloading.
+const DefaultReloadDebounce = 250 * time.Millisecond
+
+// Reloader holds the current configuration and reloads it from its sources
+// on demand, notifying subscribers of the reloadable parts that changed.
+type Reloader struct {
+	opts   Options
+	logger *slog.Logger
+
+	current atomic.Pointer[Config]
+
+	// mu serialises reloads so subscribers see changes in order.
+	mu          sync.Mutex
+	subscribers []Subscriber
+}
+
+// Subscriber prepares for a reloaded configuration. It returns an error to
+// reject the configuration, or a function putting it into effect that is
+// only called once every subscriber has accepted it.
+type Subscriber func(cfg *Config) (apply func(), err error)
+
+// NewReloader returns a Reloader starting from cfg, which must have been
+// loaded from opts.
+func NewReloader(opts Options, cfg *Config, logger *slog.Logger) *Reloader {
+	r := &Reloader{opts: opts, logger: logger}
+	r.current.Store(cfg)
+	return r
+}
+
+// Current returns the configuration in effect. It must not be modified.
+func (r *Reloader) Current() *Config {
+	return r.current.Load()
+}
+
+// Subscribe registers fn to be called with the new configuration on each
+// reload that changes a reloadable key.
+func (r *Reloader) Subscribe(fn Subscriber) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscribers = append(r.subscribers, fn)
+}
+
+// Reload loads the configuration again. A configuration that is invalid or
+// that a subscriber rejects is rejected, keeping the current one. Otherwise
+// the reloadable keys are swapped in, leaving the rest as they were, and
+// subscribers apply it.
+func (r *Reloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	loaded, err := Load(r.opts)
+	if err != nil {
+		r.logger.Error("rejected config reload, keeping current config", "error", err)
+		return err
+	}
+
+	current := r.Current()
+	next := *current
+	nextFields := configFields(&next)
+	loadedFields := configFields(loaded)
+
+	var changed bool
+	for _, c := range diff(current, loaded) {
+		if !reloadable(c.Key) {
+			r.logger.Warn("config change requires a restart", "key", c.Key, "old", c.Old, "new", c.New)
+			continue
+		}
+		r.logger.Info("config changed", "key", c.Key, "old", c.Old, "new", c.New)
+		nextFields[c.Key].Set(loadedFields[c.Key])
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+
+	applies := make([]func(), 0, len(r.subscribers))
+	for _, fn := range r.subscribers {
+		apply, err := fn(&next)
+		if err != nil {
+			r.logger.Error("rejected config reload, keeping current config", "error", err)
+			return err
+		}
+		applies = append(applies, apply)
+	}
+
+	r.current.Store(&next)
+	for _, apply := range applies {
+		apply()
+	}
+
+	return nil
+}
+
+// Watch reloads the configuration whenever the config file changes or a
+// value is received on signals, until ctx is done. The file's directory is
+// watched rather than the file itself so editors and orchestrators that
+// replace the file are noticed. Events for other files in the directory are
+// ignored, apart from the ..data symlink Kubernetes swaps when updating a
+// mounted ConfigMap.
+func (r *Reloader) Watch(ctx context.Context, signals <-chan os.Signal, debounce time.Duration) error {
+	var events <-chan fsnotify.Event
+	var errs <-chan error
+	if r.opts.Path != "" {
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			return fmt.Errorf("watching config: %w", err)
+		}
+		defer watcher.Close()
+
+		if err := watcher.Add(filepath.Dir(r.opts.Path)); err != nil {
+			return fmt.Errorf("watching config: %w", err)
+		}
+		events, errs = watcher.Events, watcher.Errors
+	}
+
+	// Nil until a file event arrives, so a burst of events causes a single
+	// reload once it settles.
+	var settled <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-signals:
+			r.Reload()
+		case event := <-events:
+			if name := filepath.Base(event.Name); name == filepath.Base(r.opts.Path) || name == configMapDataDir {
+				settled = time.After(debounce)
+			}
+		case <-settled:
+			settled = nil
+			r.Reload()
+		case err := <-errs:
+			r.logger.Error("error watching config", "error", err)
+		}
+	}
+}
+
+// change is a config key whose value differs between two configurations.
+type change struct {
+	Key string
+	Old any
+	New any
+}
+
+// diff returns the keys whose values differ between a and b, sorted by key.
+// Values that may hold credentials are redacted.
+func diff(a, b *Config) []change {
+	aFields, bFields := configFields(a), configFields(b)
+
+	var changes []change
+	for key, aField := range aFields {
+		bField := bFields[key]
+		if reflect.DeepEqual(aField.Interface(), bField.Interface()) {
+			continue
+		}
+		changes = append(changes, change{
+			Key: key,
+			Old: displayValue(key, aField),
+			New: displayValue(key, bField),
+		})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+
+	return changes
+}
+
+func displayValue(key string, field reflect.Value) any {
+	if strings.HasSuffix(key, ".uri") || strings.HasSuffix(key, ".api_keys") {
+		return "[redacted]"
+	}
+	if field.Kind() == reflect.Pointer {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+	if d, ok := field.Interface().(time.Duration); ok {
+		return d.String()
+	}
+	return field.Interface()
+}
+
+func reloadable(key string) bool {
+	for _, k := range reloadableKeys {
+		if key == k || (strings.HasSuffix(k, ".") && strings.HasPrefix(key, k)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,315 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix starts the name of every environment variable overriding a
+// config key, e.g. MOVIES_API_MONGODB_URI for mongodb.uri.
+const EnvPrefix = "MOVIES_API_"
+
+// serviceLinkScheme starts the value Kubernetes gives MOVIES_API_PORT, one
+// of the service link variables it sets for a service named movies-api.
+const serviceLinkScheme = "tcp://"
+
+// fileSuffix marks an environment variable naming a file to read the value
+// from, so secrets can be mounted rather than passed in the environment.
+const fileSuffix = "_FILE"
+
+// Options lists where configuration is loaded from. Later sources take
+// precedence: defaults, then the YAML file at Path, then Environ, then
+// Overrides.
+type Options struct {
+	// Path is the YAML config file, no file is read when empty.
+	Path string
+	// Environ holds environment variables as KEY=value pairs, usually
+	// os.Environ().
+	Environ []string
+	// Overrides maps config keys, such as mongodb.uri, to values. It is
+	// usually filled in by BindFlags.
+	Overrides Overrides
+	// Logger reports environment variables that are ignored, slog.Default()
+	// is used when nil.
+	Logger *slog.Logger
+}
+
+// Overrides maps dotted config keys to values.
+type Overrides map[string]string
+
+// Defaults returns the configuration used for anything not set by another
+// source. MongoDB client options have no defaults here so that those in the
+// URI take effect.
+func Defaults() Config {
+	return Config{
+		Port: "8080",
+		HTTP: HTTP{
+			DrainDelay:        5 * time.Second,
+			ShutdownTimeout:   15 * time.Second,
+			ReadTimeout:       30 * time.Second,
+			ReadHeaderTimeout: 10 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			MaxHeaderBytes:    1 << 20,
+			MaxBodyBytes:      1 << 20,
+		},
+		Reference: Reference{
+			RefreshInterval: 10 * time.Minute,
+		},
+		Logging: Logging{
+			Level:  "info",
+			Format: "json",
+		},
+		Idempotency: Idempotency{
+			TTL: 24 * time.Hour,
+		},
+		RateLimit: RateLimit{
+			Read:  RateLimitRule{Requests: 300, Period: time.Minute, Burst: 60},
+			Write: RateLimitRule{Requests: 30, Period: time.Minute, Burst: 10},
+		},
+	}
+}
+
+// Load builds the configuration from the sources in opts and validates it.
+func Load(opts Options) (*Config, error) {
+	cfg := Defaults()
+
+	if opts.Path != "" {
+		data, err := os.ReadFile(opts.Path)
+		if err != nil {
+			return nil, fmt.Errorf("reading config file: %w", err)
+		}
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("parsing yaml: %w", err)
+		}
+	}
+
+	fields := configFields(&cfg)
+
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	env, err := envOverrides(opts.Environ, fields, logger)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyOverrides(fields, env, func(key string) string {
+		return "environment variable " + EnvName(key)
+	}); err != nil {
+		return nil, err
+	}
+	if err := applyOverrides(fields, opts.Overrides, func(key string) string {
+		return "flag -" + key
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := validate(&cfg); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+// Keys returns every config key that can be overridden, sorted.
+func Keys() []string {
+	var cfg Config
+	fields := configFields(&cfg)
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// EnvName returns the environment variable overriding key.
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// BindFlags registers a flag on fs for every config key, e.g.
+// -mongodb.uri, and returns the overrides they are collected into once fs
+// is parsed.
+func BindFlags(fs *flag.FlagSet) Overrides {
+	overrides := Overrides{}
+
+	var cfg Config
+	fields := configFields(&cfg)
+	for _, key := range Keys() {
+		usage := fmt.Sprintf("overrides %s, also settable with %s", key, EnvName(key))
+		if fields[key].Kind() == reflect.Bool {
+			fs.Var(boolOverride{key: key, overrides: overrides}, key, usage)
+			continue
+		}
+		fs.Var(override{key: key, overrides: overrides}, key, usage)
+	}
+
+	return overrides
+}
+
+type override struct {
+	key       string
+	overrides Overrides
+}
+
+func (o override) String() string {
+	return o.overrides[o.key]
+}
+
+func (o override) Set(value string) error {
+	o.overrides[o.key] = value
+	return nil
+}
+
+// boolOverride lets boolean keys be set with a bare flag, e.g.
+// -tracing.enabled.
+type boolOverride override
+
+func (o boolOverride) String() string {
+	return override(o).String()
+}
+
+func (o boolOverride) Set(value string) error {
+	return override(o).Set(value)
+}
+
+func (o boolOverride) IsBoolFlag() bool {
+	return true
+}
+
+// configFields maps the dotted yaml key of every leaf field in cfg to the
+// field itself.
+func configFields(cfg *Config) map[string]reflect.Value {
+	fields := make(map[string]reflect.Value)
+
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+
+			field := v.Field(i)
+			if field.Kind() == reflect.Struct && field.Type() != reflect.TypeOf(time.Duration(0)) {
+				walk(field, prefix+name+".")
+				continue
+			}
+			fields[prefix+name] = field
+		}
+	}
+	walk(reflect.ValueOf(cfg).Elem(), "")
+
+	return fields
+}
+
+// envOverrides collects the values of environment variables naming config
+// keys, reading those with the _FILE suffix from the named file. Unknown
+// variables with the prefix are logged and ignored, so typos are noticed
+// without variables set by the platform stopping the service. The same goes
+// for a port given as a tcp:// address, which Kubernetes sets rather than
+// an operator.
+func envOverrides(environ []string, fields map[string]reflect.Value, logger *slog.Logger) (Overrides, error) {
+	keys := make(map[string]string, len(fields))
+	for key := range fields {
+		keys[EnvName(key)] = key
+	}
+
+	overrides := Overrides{}
+	fromFile := make(map[string]bool)
+	for _, kv := range environ {
+		name, value, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(name, EnvPrefix) {
+			continue
+		}
+
+		if key, ok := keys[name]; ok {
+			if key == "port" && strings.HasPrefix(value, serviceLinkScheme) {
+				logger.Warn("ignoring Kubernetes service link variable", "name", name)
+				continue
+			}
+			if fromFile[key] {
+				return nil, fmt.Errorf("both %s and %s are set", name, name+fileSuffix)
+			}
+			overrides[key] = value
+			continue
+		}
+
+		key, ok := keys[strings.TrimSuffix(name, fileSuffix)]
+		if !strings.HasSuffix(name, fileSuffix) || !ok {
+			logger.Warn("ignoring unknown environment variable", "name", name)
+			continue
+		}
+		if _, set := overrides[key]; set {
+			return nil, fmt.Errorf("both %s and %s are set", EnvName(key), name)
+		}
+
+		data, err := os.ReadFile(value)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", name, err)
+		}
+		overrides[key] = strings.TrimRight(string(data), "\r\n")
+		fromFile[key] = true
+	}
+
+	return overrides, nil
+}
+
+// applyOverrides sets each field named in overrides, using source to name
+// where a key came from in errors.
+func applyOverrides(fields map[string]reflect.Value, overrides Overrides, source func(key string) string) error {
+	for key, value := range overrides {
+		field, ok := fields[key]
+		if !ok {
+			return fmt.Errorf("unknown config key %q", key)
+		}
+		if err := setField(field, value); err != nil {
+			return fmt.Errorf("parsing %s: %w", source(key), err)
+		}
+	}
+
+	return nil
+}
+
+// setField parses value into field. Strings are taken as is, lists may be
+// comma separated and everything else is parsed as YAML, so values are
+// written the same way as in the config file.
+func setField(field reflect.Value, value string) error {
+	switch {
+	case field.Kind() == reflect.String:
+		field.SetString(value)
+		return nil
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String &&
+		!strings.HasPrefix(strings.TrimSpace(value), "["):
+		var values []string
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		field.Set(reflect.ValueOf(values))
+		return nil
+	}
+
+	parsed := reflect.New(field.Type())
+	if err := yaml.Unmarshal([]byte(value), parsed.Interface()); err != nil {
+		return err
+	}
+	field.Set(parsed.Elem())
+
+	return nil
+}
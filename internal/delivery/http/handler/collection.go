@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yasv98/movies-api/internal/delivery/http/middleware"
+	"github.com/yasv98/movies-api/internal/domain"
+	"github.com/yasv98/movies-api/internal/service"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CollectionHandler struct {
+	collectionService *service.CollectionService
+}
+
+func NewCollectionHandler(collectionService *service.CollectionService) *CollectionHandler {
+	return &CollectionHandler{
+		collectionService: collectionService,
+	}
+}
+
+func (h *CollectionHandler) CreateCollection(c *gin.Context) {
+	var collection domain.Collection
+	if err := c.ShouldBindJSON(&collection); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.collectionService.CreateCollection(c.Request.Context(), middleware.UserID(c), &collection); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, collection)
+}
+
+func (h *CollectionHandler) UpdateCollection(c *gin.Context) {
+	collectionId, err := primitive.ObjectIDFromHex(c.Param("collectionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var collection domain.Collection
+	if err := c.ShouldBindJSON(&collection); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	collection.ID = collectionId
+	if err := h.collectionService.UpdateCollection(c.Request.Context(), middleware.UserID(c), &collection); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, collection)
+}
+
+func (h *CollectionHandler) DeleteCollection(c *gin.Context) {
+	collectionId, err := primitive.ObjectIDFromHex(c.Param("collectionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.collectionService.DeleteCollection(c.Request.Context(), middleware.UserID(c), collectionId); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *CollectionHandler) GetCollection(c *gin.Context) {
+	collectionId, err := primitive.ObjectIDFromHex(c.Param("collectionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	collection, err := h.collectionService.GetCollection(c.Request.Context(), middleware.UserID(c), collectionId)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, collection)
+}
+
+func (h *CollectionHandler) ListPublicCollections(c *gin.Context) {
+	page, limit, err := parsePagination(c, 20)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	collections, err := h.collectionService.ListPublicCollections(c.Request.Context(), page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, collections)
+}
+
+func (h *CollectionHandler) ListUserCollections(c *gin.Context) {
+	page, limit, err := parsePagination(c, 20)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	collections, err := h.collectionService.ListUserCollections(c.Request.Context(), middleware.UserID(c), page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, collections)
+}
+
+func (h *CollectionHandler) writeError(c *gin.Context, err error) {
+	switch err {
+	case domain.ErrInvalidCollectionTitle,
+		domain.ErrInvalidCollectionDescription,
+		domain.ErrInvalidCollectionVisibility,
+		domain.ErrInvalidCollectionMovies,
+		domain.ErrMovieNotFound:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case domain.ErrCollectionNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case domain.ErrNotCollectionOwner:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
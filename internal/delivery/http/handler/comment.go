@@ -1,24 +1,41 @@
 package handler
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/yasv98/movies-api/internal/delivery/http/middleware"
 	"github.com/yasv98/movies-api/internal/domain"
+	"github.com/yasv98/movies-api/internal/logging"
 	"github.com/yasv98/movies-api/internal/service"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// IdempotencyKeyHeader carries a client-chosen key making a request safe to
+// retry. Retries with the same key get the original response.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader marks a response replayed for a retried request.
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
 type CommentHandler struct {
-	commentService *service.CommentService
+	commentService     *service.CommentService
+	idempotencyService *service.IdempotencyService
 }
 
-func NewCommentHandler(commentService *service.CommentService) *CommentHandler {
+func NewCommentHandler(commentService *service.CommentService, idempotencyService *service.IdempotencyService) *CommentHandler {
 	return &CommentHandler{
-		commentService: commentService,
+		commentService:     commentService,
+		idempotencyService: idempotencyService,
 	}
 }
 
+// CreateComment creates a comment. Requests with an Idempotency-Key header
+// are only acted on once per key, retries get the original response.
+//
 // TODO: Check movie exists in DB before creating comment. Since sample data
 // has cases where comments have movie ID's that don't exist in the movie
 // sample data, have not implemented this check for consistency with data.
@@ -34,14 +51,83 @@ func (h *CommentHandler) CreateComment(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-
 	comment.MovieID = movieId
-	if err := h.commentService.CreateComment(c.Request.Context(), &comment); err != nil {
+
+	key := c.GetHeader(IdempotencyKeyHeader)
+	if key == "" {
+		c.JSON(h.createComment(c.Request.Context(), &comment))
+		return
+	}
+
+	// Keys are scoped to the movie and user so clients cannot collide.
+	scope := "create-comment:" + movieId.Hex() + ":" + middleware.UserID(c)
+	original, err := h.idempotencyService.Begin(c.Request.Context(), scope, key, comment)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidIdempotencyKey:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case domain.ErrIdempotencyKeyReused:
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case domain.ErrIdempotencyKeyInProgress:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	if original != nil {
+		c.Header(IdempotentReplayedHeader, "true")
+		c.Data(original.Status, original.ContentType, original.Body)
+		return
+	}
+
+	status, response := h.createComment(c.Request.Context(), &comment)
+
+	// The outcome is recorded even if the client has gone away, as that is
+	// when it is most likely to retry.
+	ctx := context.WithoutCancel(c.Request.Context())
+	if status >= http.StatusInternalServerError {
+		// Failures may be transient, so the retry is let through.
+		if err := h.idempotencyService.Release(ctx, scope, key); err != nil {
+			logging.FromContext(ctx).Error("error releasing idempotency key", "error", err)
+		}
+		c.JSON(status, response)
+		return
+	}
+
+	body, err := json.Marshal(response)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	if err := h.idempotencyService.Complete(ctx, scope, key, status, jsonContentType, body); err != nil {
+		logging.FromContext(ctx).Error("error storing idempotent response", "error", err)
+	}
+	c.Data(status, jsonContentType, body)
+}
+
+const jsonContentType = "application/json; charset=utf-8"
+
+// createComment creates the comment, returning the status and body to
+// respond with.
+func (h *CommentHandler) createComment(ctx context.Context, comment *domain.Comment) (int, any) {
+	if err := h.commentService.CreateComment(ctx, comment); err != nil {
+		if err == domain.ErrParentCommentNotFound {
+			return http.StatusBadRequest, gin.H{"error": err.Error()}
+		}
+		if errors.Is(err, domain.ErrCommentRejected) {
+			return http.StatusUnprocessableEntity, gin.H{"error": err.Error()}
+		}
+		return http.StatusInternalServerError, gin.H{"error": err.Error()}
+	}
+
+	// Held comments are stored but not visible until a moderator approves
+	// them.
+	if comment.Status == domain.CommentStatusPending {
+		return http.StatusAccepted, comment
+	}
 
-	c.JSON(http.StatusCreated, comment)
+	return http.StatusCreated, comment
 }
 
 func (h *CommentHandler) UpdateComment(c *gin.Context) {
@@ -70,10 +156,23 @@ func (h *CommentHandler) UpdateComment(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
 		}
+		if err == domain.ErrCommentNotEditable {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, domain.ErrCommentRejected) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	if comment.Status == domain.CommentStatusPending {
+		c.JSON(http.StatusAccepted, comment)
+		return
+	}
+
 	c.JSON(http.StatusOK, comment)
 }
 
@@ -131,11 +230,119 @@ func (h *CommentHandler) GetMovieComments(c *gin.Context) {
 		return
 	}
 
-	comments, err := h.commentService.GetMovieComments(c.Request.Context(), movieId)
+	sort := domain.CommentSort(c.Query("sort"))
+	if view := c.Query("view"); view != "" {
+		thread, err := h.commentService.GetMovieCommentThread(c.Request.Context(), movieId, domain.CommentView(view), sort)
+		if err != nil {
+			if err == domain.ErrInvalidCommentView || err == domain.ErrInvalidCommentSort {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, thread)
+		return
+	}
+
+	comments, err := h.commentService.GetMovieComments(c.Request.Context(), movieId, sort)
 	if err != nil {
+		if err == domain.ErrInvalidCommentSort {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, comments)
 }
+
+func (h *CommentHandler) GetCommentReplies(c *gin.Context) {
+	movieId, err := primitive.ObjectIDFromHex(c.Param("movieId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	commentId, err := primitive.ObjectIDFromHex(c.Param("commentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	replies, err := h.commentService.GetCommentReplies(c.Request.Context(), movieId, commentId)
+	if err != nil {
+		if err == domain.ErrCommentNotFoundForMovie {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, replies)
+}
+
+func (h *CommentHandler) AddReaction(c *gin.Context) {
+	movieId, err := primitive.ObjectIDFromHex(c.Param("movieId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	commentId, err := primitive.ObjectIDFromHex(c.Param("commentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req struct {
+		Type domain.ReactionType `json:"type" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := h.commentService.React(c.Request.Context(), movieId, commentId, middleware.UserID(c), req.Type)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidReactionType:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case domain.ErrCommentNotFoundForMovie:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+func (h *CommentHandler) RemoveReaction(c *gin.Context) {
+	movieId, err := primitive.ObjectIDFromHex(c.Param("movieId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	commentId, err := primitive.ObjectIDFromHex(c.Param("commentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.commentService.RemoveReaction(c.Request.Context(), movieId, commentId, middleware.UserID(c)); err != nil {
+		if err == domain.ErrCommentNotFoundForMovie {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yasv98/movies-api/internal/health"
+)
+
+type HealthHandler struct {
+	registry *health.Registry
+}
+
+func NewHealthHandler(registry *health.Registry) *HealthHandler {
+	return &HealthHandler{
+		registry: registry,
+	}
+}
+
+// Liveness reports that the process is up and serving requests. It
+// deliberately checks nothing else, so a dependency outage does not get the
+// process restarted.
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": health.StatusOK})
+}
+
+// Readiness runs the registered checks, responding with 503 if any fail.
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	report := h.registry.Check(c.Request.Context())
+	if report.Status != health.StatusOK {
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yasv98/movies-api/internal/delivery/http/middleware"
+	"github.com/yasv98/movies-api/internal/domain"
+	"github.com/yasv98/movies-api/internal/service"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ModerationHandler struct {
+	moderationService *service.ModerationService
+}
+
+func NewModerationHandler(moderationService *service.ModerationService) *ModerationHandler {
+	return &ModerationHandler{
+		moderationService: moderationService,
+	}
+}
+
+func (h *ModerationHandler) FlagComment(c *gin.Context) {
+	movieId, err := primitive.ObjectIDFromHex(c.Param("movieId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	commentId, err := primitive.ObjectIDFromHex(c.Param("commentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.moderationService.FlagComment(c.Request.Context(), movieId, commentId, middleware.UserID(c), req.Reason); err != nil {
+		switch err {
+		case domain.ErrInvalidFlagReason:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case domain.ErrCommentNotFoundForMovie:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case domain.ErrAlreadyFlagged:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+func (h *ModerationHandler) GetQueue(c *gin.Context) {
+	page, limit, err := parsePagination(c, 20)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comments, err := h.moderationService.GetQueue(c.Request.Context(), middleware.UserID(c), page, limit)
+	if err != nil {
+		if err == domain.ErrNotModerator {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, comments)
+}
+
+func (h *ModerationHandler) ApproveComment(c *gin.Context) {
+	h.decide(c, h.moderationService.ApproveComment)
+}
+
+func (h *ModerationHandler) RejectComment(c *gin.Context) {
+	h.decide(c, h.moderationService.RejectComment)
+}
+
+type decisionFunc func(ctx context.Context, commentID primitive.ObjectID, userID, note string) (*domain.Comment, error)
+
+func (h *ModerationHandler) decide(c *gin.Context, decide decisionFunc) {
+	commentId, err := primitive.ObjectIDFromHex(c.Param("commentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// The note is optional, so an empty body is allowed.
+	var req struct {
+		Note string `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := decide(c.Request.Context(), commentId, middleware.UserID(c), req.Note)
+	if err != nil {
+		switch err {
+		case domain.ErrNotModerator:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case domain.ErrCommentNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case domain.ErrCommentNotInQueue:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
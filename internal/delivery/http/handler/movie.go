@@ -3,12 +3,18 @@ package handler
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/yasv98/movies-api/internal/domain"
+	"github.com/yasv98/movies-api/internal/fieldset"
 	"github.com/yasv98/movies-api/internal/service"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// movieFields are the fields clients may select with the fields parameter.
+var movieFields = fieldset.New(domain.Movie{})
+
 type MovieHandler struct {
 	movieUsecase *service.MovieService
 }
@@ -26,34 +32,234 @@ func (h *MovieHandler) GetMovie(c *gin.Context) {
 		return
 	}
 
-	movie, err := h.movieUsecase.GetMovie(c.Request.Context(), id)
+	fields, err := movieFields.Parse(c.Query("fields"))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	movie, err := h.movieUsecase.GetMovie(c.Request.Context(), id, fields.Projection())
+	if err != nil {
+		if err == domain.ErrMovieNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, movie)
+	response, err := fields.Apply(movie)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
 func (h *MovieHandler) GetMovies(c *gin.Context) {
-	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
-	if err != nil || page <= 0 {
+	page, limit, err := parsePagination(c, 10)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	if err != nil || limit <= 0 {
+	minRating, err := strconv.ParseFloat(c.DefaultQuery("min_community_rating", "0"), 64)
+	if err != nil || minRating < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "min_community_rating must be a non-negative number"})
+		return
+	}
+
+	minVotes, err := strconv.Atoi(c.DefaultQuery("min_community_votes", "0"))
+	if err != nil || minVotes < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "min_community_votes must be a non-negative integer"})
+		return
+	}
+
+	fields, err := movieFields.Parse(c.Query("fields"))
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	titleFilter := c.Query("title")
-	movies, err := h.movieUsecase.GetMovies(c.Request.Context(), titleFilter, page, limit)
+	// Listing specific movies replaces the usual search.
+	if ids, ok := c.GetQuery("ids"); ok {
+		h.getMoviesByIDs(c, strings.Split(ids, ","), fields)
+		return
+	}
+
+	query := domain.MovieQuery{
+		Title:              c.Query("title"),
+		MinCommunityRating: minRating,
+		MinCommunityVotes:  minVotes,
+		Sort:               domain.MovieSort(c.Query("sort")),
+		Page:               page,
+		Limit:              limit,
+		Fields:             fields.Projection(),
+	}
+	movies, err := h.movieUsecase.GetMovies(c.Request.Context(), query)
+	if err != nil {
+		if err == domain.ErrInvalidMovieSort {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if movies == nil {
+		movies = []domain.Movie{}
+	}
+	response, err := fields.Apply(movies)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	// The plain list is kept as the response unless facets are asked for,
+	// so existing clients are unaffected.
+	facets := parseFacets(c.Query("facets"))
+	if len(facets) == 0 {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	counts, err := h.movieUsecase.GetMovieFacets(c.Request.Context(), query, facets)
+	if err != nil {
+		if err == domain.ErrInvalidFacet {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"movies": response, "facets": counts})
+}
+
+// parseFacets splits a comma separated list of facets, ignoring blanks.
+func parseFacets(value string) []domain.MovieFacet {
+	var facets []domain.MovieFacet
+	for _, facet := range strings.Split(value, ",") {
+		if facet = strings.TrimSpace(facet); facet != "" {
+			facets = append(facets, domain.MovieFacet(facet))
+		}
+	}
+	return facets
+}
+
+func (h *MovieHandler) GetSimilarMovies(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("movieId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidLimit.Error()})
+		return
+	}
+
+	movies, err := h.movieUsecase.GetSimilarMovies(c.Request.Context(), id, limit)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidLimit:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case domain.ErrMovieNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
 	c.JSON(http.StatusOK, movies)
 }
+
+func (h *MovieHandler) Autocomplete(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidLimit.Error()})
+		return
+	}
+
+	movies, err := h.movieUsecase.Autocomplete(c.Request.Context(), c.Query("prefix"), limit)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidLimit, domain.ErrInvalidPrefix:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, movies)
+}
+
+// CustomMethod dispatches custom methods on the movie collection, such as
+// POST /movies:batchGet. Gin has no way to match a literal colon, so the
+// route captures it along with the method name.
+func (h *MovieHandler) CustomMethod(c *gin.Context) {
+	switch c.Param("method") {
+	case ":batchGet":
+		h.BatchGetMovies(c)
+	default:
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown method"})
+	}
+}
+
+// BatchGetMovies fetches the movies listed in the request body, as an
+// alternative to GetMovies with ids for batches too long for a URL.
+func (h *MovieHandler) BatchGetMovies(c *gin.Context) {
+	var req struct {
+		IDs    []string `json:"ids" binding:"required"`
+		Fields []string `json:"fields"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	fields, err := movieFields.Parse(strings.Join(req.Fields, ","))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.getMoviesByIDs(c, req.IDs, fields)
+}
+
+func (h *MovieHandler) getMoviesByIDs(c *gin.Context, hexIDs []string, fields *fieldset.Selection) {
+	ids := make([]primitive.ObjectID, 0, len(hexIDs))
+	for _, hexID := range hexIDs {
+		if hexID = strings.TrimSpace(hexID); hexID == "" {
+			continue
+		}
+		id, err := primitive.ObjectIDFromHex(hexID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ids = append(ids, id)
+	}
+
+	batch, err := h.movieUsecase.GetMoviesByIDs(c.Request.Context(), ids, fields.Projection())
+	if err != nil {
+		if err == domain.ErrInvalidBatchSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	movies, err := fields.Apply(batch.Movies)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"movies": movies, "not_found": batch.NotFound})
+}
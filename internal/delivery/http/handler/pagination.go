@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errInvalidPage  = errors.New("page must be a positive integer")
+	errInvalidLimit = errors.New("limit must be a positive integer")
+)
+
+// parsePagination reads the page and limit query parameters, defaulting to
+// the first page of defaultLimit items.
+func parsePagination(c *gin.Context, defaultLimit int) (page, limit int, err error) {
+	page, err = strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page <= 0 {
+		return 0, 0, errInvalidPage
+	}
+
+	limit, err = strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultLimit)))
+	if err != nil || limit <= 0 {
+		return 0, 0, errInvalidLimit
+	}
+
+	return page, limit, nil
+}
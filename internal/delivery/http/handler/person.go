@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yasv98/movies-api/internal/domain"
+	"github.com/yasv98/movies-api/internal/service"
+)
+
+type PersonHandler struct {
+	personService *service.PersonService
+}
+
+func NewPersonHandler(personService *service.PersonService) *PersonHandler {
+	return &PersonHandler{
+		personService: personService,
+	}
+}
+
+func (h *PersonHandler) SearchPeople(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidLimit.Error()})
+		return
+	}
+
+	people, err := h.personService.SearchPeople(c.Request.Context(), c.Query("name"), limit)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidLimit, domain.ErrInvalidPersonName:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, people)
+}
+
+func (h *PersonHandler) GetPersonMovies(c *gin.Context) {
+	page, limit, err := parsePagination(c, 20)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sort := domain.PersonMovieSort(c.Query("sort"))
+	filmography, err := h.personService.GetFilmography(c.Request.Context(), c.Param("name"), sort, page, limit)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidPersonSort, domain.ErrInvalidPersonName, domain.ErrInvalidLimit:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case domain.ErrPersonNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, filmography)
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yasv98/movies-api/internal/delivery/http/middleware"
+	"github.com/yasv98/movies-api/internal/domain"
+	"github.com/yasv98/movies-api/internal/service"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type RatingHandler struct {
+	ratingService *service.RatingService
+}
+
+func NewRatingHandler(ratingService *service.RatingService) *RatingHandler {
+	return &RatingHandler{
+		ratingService: ratingService,
+	}
+}
+
+func (h *RatingHandler) SetRating(c *gin.Context) {
+	movieId, err := primitive.ObjectIDFromHex(c.Param("movieId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req struct {
+		Score int `json:"score" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rating, err := h.ratingService.SetRating(c.Request.Context(), movieId, middleware.UserID(c), req.Score)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidRatingScore:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case domain.ErrMovieNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, rating)
+}
+
+func (h *RatingHandler) GetRating(c *gin.Context) {
+	movieId, err := primitive.ObjectIDFromHex(c.Param("movieId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rating, err := h.ratingService.GetRating(c.Request.Context(), movieId, middleware.UserID(c))
+	if err != nil {
+		if err == domain.ErrRatingNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rating)
+}
+
+func (h *RatingHandler) DeleteRating(c *gin.Context) {
+	movieId, err := primitive.ObjectIDFromHex(c.Param("movieId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.ratingService.DeleteRating(c.Request.Context(), movieId, middleware.UserID(c)); err != nil {
+		if err == domain.ErrRatingNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
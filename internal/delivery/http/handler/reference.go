@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yasv98/movies-api/internal/domain"
+	"github.com/yasv98/movies-api/internal/service"
+)
+
+type ReferenceHandler struct {
+	referenceService *service.ReferenceService
+}
+
+func NewReferenceHandler(referenceService *service.ReferenceService) *ReferenceHandler {
+	return &ReferenceHandler{
+		referenceService: referenceService,
+	}
+}
+
+// GetValues returns a handler listing the values of kind.
+func (h *ReferenceHandler) GetValues(kind domain.ReferenceKind) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		values, err := h.referenceService.GetValues(c.Request.Context(), kind)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, values)
+	}
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yasv98/movies-api/internal/domain"
+	"github.com/yasv98/movies-api/internal/service"
+)
+
+type StatsHandler struct {
+	statsService *service.StatsService
+}
+
+func NewStatsHandler(statsService *service.StatsService) *StatsHandler {
+	return &StatsHandler{
+		statsService: statsService,
+	}
+}
+
+func (h *StatsHandler) MoviesPerYear(c *gin.Context) {
+	counts, err := h.statsService.MoviesPerYear(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, counts)
+}
+
+func (h *StatsHandler) GenreDistribution(c *gin.Context) {
+	counts, err := h.statsService.GenreDistribution(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, counts)
+}
+
+func (h *StatsHandler) RatingsByGenreDecade(c *gin.Context) {
+	ratings, err := h.statsService.RatingsByGenreDecade(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ratings)
+}
+
+func (h *StatsHandler) TopDirectors(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidLimit.Error()})
+		return
+	}
+
+	minVotes, err := strconv.Atoi(c.DefaultQuery("min_votes", "10000"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidMinVotes.Error()})
+		return
+	}
+
+	directors, err := h.statsService.TopDirectors(c.Request.Context(), domain.TopDirectorsQuery{
+		MinVotes: minVotes,
+		Limit:    limit,
+	})
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidLimit, domain.ErrInvalidMinVotes:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, directors)
+}
+
+func (h *StatsHandler) CommentActivity(c *gin.Context) {
+	query := domain.CommentActivityQuery{
+		Interval: domain.StatsInterval(c.DefaultQuery("interval", string(domain.StatsIntervalMonth))),
+	}
+
+	var err error
+	if from := c.Query("from"); from != "" {
+		if query.From, err = time.Parse(time.RFC3339, from); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be an RFC 3339 time"})
+			return
+		}
+	}
+	if to := c.Query("to"); to != "" {
+		if query.To, err = time.Parse(time.RFC3339, to); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be an RFC 3339 time"})
+			return
+		}
+	}
+
+	activity, err := h.statsService.CommentActivity(c.Request.Context(), query)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidStatsInterval, domain.ErrInvalidStatsRange:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, activity)
+}
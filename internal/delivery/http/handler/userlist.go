@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yasv98/movies-api/internal/delivery/http/middleware"
+	"github.com/yasv98/movies-api/internal/domain"
+	"github.com/yasv98/movies-api/internal/service"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UserListHandler serves the current user's movie lists. Each method
+// returns a handler bound to one list, so the watchlist and favorites share
+// the same endpoints.
+type UserListHandler struct {
+	userListService *service.UserListService
+}
+
+func NewUserListHandler(userListService *service.UserListService) *UserListHandler {
+	return &UserListHandler{
+		userListService: userListService,
+	}
+}
+
+func (h *UserListHandler) GetList(list domain.UserListKind) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, limit, err := parsePagination(c, 20)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		items, err := h.userListService.GetList(c.Request.Context(), middleware.UserID(c), list, page, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, items)
+	}
+}
+
+func (h *UserListHandler) AddMovie(list domain.UserListKind) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			MovieID string `json:"movie_id" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		movieId, err := primitive.ObjectIDFromHex(req.MovieID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		entry, err := h.userListService.AddMovie(c.Request.Context(), middleware.UserID(c), list, movieId)
+		if err != nil {
+			switch err {
+			case domain.ErrMovieNotFound:
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			case domain.ErrMovieAlreadyInList:
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			}
+			return
+		}
+
+		c.JSON(http.StatusCreated, entry)
+	}
+}
+
+func (h *UserListHandler) RemoveMovie(list domain.UserListKind) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		movieId, err := primitive.ObjectIDFromHex(c.Param("movieId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := h.userListService.RemoveMovie(c.Request.Context(), middleware.UserID(c), list, movieId); err != nil {
+			if err == domain.ErrMovieNotInList {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func (h *UserListHandler) Reorder(list domain.UserListKind) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			MovieIDs []string `json:"movie_ids" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		movieIds := make([]primitive.ObjectID, 0, len(req.MovieIDs))
+		for _, hex := range req.MovieIDs {
+			id, err := primitive.ObjectIDFromHex(hex)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			movieIds = append(movieIds, id)
+		}
+
+		if err := h.userListService.Reorder(c.Request.Context(), middleware.UserID(c), list, movieIds); err != nil {
+			if err == domain.ErrInvalidListOrder {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
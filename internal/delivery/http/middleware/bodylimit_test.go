@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBodyLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(BodyLimit(8))
+	router.POST("/", func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		c.String(http.StatusOK, string(body))
+	})
+
+	tests := map[string]struct {
+		body          string
+		contentLength int64
+		wantStatus    int
+	}{
+		"Within limit":         {body: "12345678", contentLength: 8, wantStatus: http.StatusOK},
+		"Declared over limit":  {body: "123456789", contentLength: 9, wantStatus: http.StatusRequestEntityTooLarge},
+		"Chunked within limit": {body: "12345678", contentLength: -1, wantStatus: http.StatusOK},
+		"Chunked over limit":   {body: "123456789", contentLength: -1, wantStatus: http.StatusRequestEntityTooLarge},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.ContentLength = tt.contentLength
+
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, req)
+
+			assert.Equal(t, tt.wantStatus, res.Code)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yasv98/movies-api/internal/logging"
+	"github.com/yasv98/movies-api/internal/ratelimit"
+)
+
+// APIKeyHeader carries the caller's API key, which takes precedence over the
+// user and IP address when identifying a client for rate limiting.
+const APIKeyHeader = "X-API-Key"
+
+// RateLimit rejects requests from clients that have used up their read or
+// write allowance with a 429 problem response. Every limited response
+// carries RateLimit-* headers describing the client's allowance. Requests
+// are let through if the limiter's store fails.
+//
+// Clients are identified by a known API key, then by X-User-ID when the
+// request came through one of trustedProxies, and otherwise by IP address.
+func RateLimit(limiter *ratelimit.Limiter, trustedProxies []netip.Prefix) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := rateLimitKey(c, limiter, trustedProxies)
+		result, rule, err := limiter.Allow(c.Request.Context(), key, isWrite(c.Request.Method))
+		if err != nil {
+			logging.FromContext(c.Request.Context()).Error("error checking rate limit", "error", err)
+			c.Next()
+			return
+		}
+		if rule.Unlimited() {
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(rule.Capacity()))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+		c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d;burst=%d", rule.Requests, ceilSeconds(rule.Period), rule.Capacity()))
+
+		if !result.Allowed {
+			retryAfter := ceilSeconds(result.RetryAfter)
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.Header("Content-Type", "application/problem+json")
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"type":   "about:blank",
+				"title":  http.StatusText(http.StatusTooManyRequests),
+				"status": http.StatusTooManyRequests,
+				"detail": fmt.Sprintf("rate limit exceeded, retry in %d seconds", retryAfter),
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// rateLimitKey identifies the client. Headers the server cannot vouch for
+// are ignored, as a client choosing its own identity could take a fresh
+// allowance with every request.
+func rateLimitKey(c *gin.Context, limiter *ratelimit.Limiter, trustedProxies []netip.Prefix) string {
+	if key := strings.TrimSpace(c.GetHeader(APIKeyHeader)); key != "" && limiter.KnownAPIKey(key) {
+		return "key:" + ratelimit.HashAPIKey(key)
+	}
+	if user := UserID(c); user != "" && fromTrustedProxy(c, trustedProxies) {
+		return "user:" + user
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// fromTrustedProxy reports whether the connection the request arrived on
+// came from one of trustedProxies.
+func fromTrustedProxy(c *gin.Context, trustedProxies []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(c.RemoteIP())
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTrustedProxies converts addresses and CIDR ranges into prefixes.
+func ParseTrustedProxies(proxies []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+	for _, proxy := range proxies {
+		if prefix, err := netip.ParsePrefix(proxy); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+func isWrite(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
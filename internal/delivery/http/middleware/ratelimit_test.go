@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yasv98/movies-api/internal/config"
+	"github.com/yasv98/movies-api/internal/ratelimit"
+)
+
+// newRateLimitRouter returns a router allowing one read and one write per
+// client, with the API key "secret" and proxies in 10.0.0.0/8 trusted.
+func newRateLimitRouter(t *testing.T) (*gin.Engine, *ratelimit.Limiter) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	policy := ratelimit.NewPolicy(config.RateLimit{
+		Enabled: true,
+		Read:    config.RateLimitRule{Requests: 60, Period: time.Minute, Burst: 1},
+		Write:   config.RateLimitRule{Requests: 60, Period: time.Minute, Burst: 1},
+		APIKeys: []string{"secret"},
+	})
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), policy)
+
+	trustedProxies, err := ParseTrustedProxies([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(RateLimit(limiter, trustedProxies))
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.POST("/", func(c *gin.Context) { c.Status(http.StatusCreated) })
+
+	return router, limiter
+}
+
+func doRequest(router *gin.Engine, method, remoteAddr string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/", nil)
+	req.RemoteAddr = remoteAddr
+	for name, value := range header {
+		req.Header.Set(name, value)
+	}
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, req)
+	return res
+}
+
+func TestRateLimit(t *testing.T) {
+	router, limiter := newRateLimitRouter(t)
+	const client = "192.0.2.1:1234"
+
+	res := doRequest(router, http.MethodGet, client, nil)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "1", res.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", res.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "1", res.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "60;w=60;burst=1", res.Header().Get("RateLimit-Policy"))
+
+	res = doRequest(router, http.MethodGet, client, nil)
+	assert.Equal(t, http.StatusTooManyRequests, res.Code)
+	assert.Equal(t, "1", res.Header().Get("Retry-After"))
+	assert.Equal(t, "application/problem+json", res.Header().Get("Content-Type"))
+	var problem map[string]any
+	require.NoError(t, json.Unmarshal(res.Body.Bytes(), &problem))
+	assert.Equal(t, float64(http.StatusTooManyRequests), problem["status"])
+	assert.Equal(t, "Too Many Requests", problem["title"])
+
+	// Writes have an allowance of their own.
+	assert.Equal(t, http.StatusCreated, doRequest(router, http.MethodPost, client, nil).Code)
+
+	// Other addresses have allowances of their own.
+	assert.Equal(t, http.StatusOK, doRequest(router, http.MethodGet, "192.0.2.2:1234", nil).Code)
+
+	limiter.SetPolicy(ratelimit.Policy{})
+	res = doRequest(router, http.MethodGet, client, nil)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Empty(t, res.Header().Get("RateLimit-Limit"))
+}
+
+func TestRateLimit_UnvalidatedIdentities(t *testing.T) {
+	router, _ := newRateLimitRouter(t)
+	const client = "192.0.2.1:1234"
+
+	assert.Equal(t, http.StatusOK, doRequest(router, http.MethodGet, client, nil).Code)
+
+	// Made up API keys and user IDs sent straight from the client do not
+	// get around the limit on its address.
+	for i := 0; i < 5; i++ {
+		res := doRequest(router, http.MethodGet, client, map[string]string{
+			APIKeyHeader: fmt.Sprintf("random-%d", i),
+			UserIDHeader: fmt.Sprintf("user-%d", i),
+		})
+		assert.Equal(t, http.StatusTooManyRequests, res.Code, "request %d", i)
+	}
+}
+
+func TestRateLimit_TrustedIdentities(t *testing.T) {
+	router, _ := newRateLimitRouter(t)
+	const client = "192.0.2.1:1234"
+	const gateway = "10.0.0.1:1234"
+
+	assert.Equal(t, http.StatusOK, doRequest(router, http.MethodGet, client, nil).Code)
+
+	// A known API key has an allowance of its own.
+	apiKey := map[string]string{APIKeyHeader: "secret"}
+	assert.Equal(t, http.StatusOK, doRequest(router, http.MethodGet, client, apiKey).Code)
+	assert.Equal(t, http.StatusTooManyRequests, doRequest(router, http.MethodGet, client, apiKey).Code)
+
+	// Users are told apart when the gateway vouches for them.
+	assert.Equal(t, http.StatusOK, doRequest(router, http.MethodGet, gateway, map[string]string{UserIDHeader: "alice"}).Code)
+	assert.Equal(t, http.StatusOK, doRequest(router, http.MethodGet, gateway, map[string]string{UserIDHeader: "bob"}).Code)
+	assert.Equal(t, http.StatusTooManyRequests, doRequest(router, http.MethodGet, gateway, map[string]string{UserIDHeader: "bob"}).Code)
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	prefixes, err := ParseTrustedProxies([]string{"10.1.2.3/8", "192.0.2.1", "::1"})
+	require.NoError(t, err)
+	assert.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("192.0.2.1/32"),
+		netip.MustParsePrefix("::1/128"),
+	}, prefixes)
+
+	_, err = ParseTrustedProxies([]string{"gateway"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader carries the ID used to correlate a request across
+// services. Inbound IDs are kept and generated ones are returned to the
+// caller.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds inbound IDs so callers cannot bloat every log
+// line.
+const maxRequestIDLength = 128
+
+const requestIDKey = "request_id"
+
+// RequestID assigns every request an ID, reusing the caller's X-Request-ID
+// when it is usable.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		c.Set(requestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// RequestIDFrom returns the ID assigned to the request by RequestID.
+func RequestIDFrom(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		// Printable ASCII only, so IDs are safe to echo and log.
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	// crypto/rand.Read never returns an error.
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserIDHeader carries the ID of the authenticated user. It is expected to
+// be set by the gateway in front of the API once the caller is
+// authenticated.
+const UserIDHeader = "X-User-ID"
+
+// RequireUser rejects requests that do not identify a user.
+func RequireUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if UserID(c) == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing " + UserIDHeader + " header"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// UserID returns the ID of the user making the request, or an empty string
+// for anonymous requests.
+func UserID(c *gin.Context) string {
+	return strings.TrimSpace(c.GetHeader(UserIDHeader))
+}
@@ -1,26 +1,102 @@
 package routes
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 	"github.com/yasv98/movies-api/internal/delivery/http/handler"
+	"github.com/yasv98/movies-api/internal/delivery/http/middleware"
+	"github.com/yasv98/movies-api/internal/domain"
 )
 
 func SetupRoutes(
 	r *gin.Engine,
 	movieHandler *handler.MovieHandler,
 	commentHandler *handler.CommentHandler,
+	moderationHandler *handler.ModerationHandler,
+	ratingHandler *handler.RatingHandler,
+	userListHandler *handler.UserListHandler,
+	collectionHandler *handler.CollectionHandler,
+	statsHandler *handler.StatsHandler,
+	personHandler *handler.PersonHandler,
+	referenceHandler *handler.ReferenceHandler,
+	healthHandler *handler.HealthHandler,
+	metricsHandler http.Handler,
+	apiMiddleware ...gin.HandlerFunc,
 ) {
-	api := r.Group("/api/v1")
+	// Operational routes, outside the versioned API as they are for the
+	// orchestrator and monitoring rather than clients.
+	r.GET("/healthz", healthHandler.Liveness)
+	r.GET("/readyz", healthHandler.Readiness)
+	r.GET("/metrics", gin.WrapH(metricsHandler))
+
+	// apiMiddleware, such as rate limiting, applies to client requests only.
+	api := r.Group("/api/v1", apiMiddleware...)
 	{
 		// Movie routes.
+		api.GET("/movies/autocomplete", movieHandler.Autocomplete)
 		api.GET("/movies/:movieId", movieHandler.GetMovie)
 		api.GET("/movies", movieHandler.GetMovies)
+		api.POST("/movies:method", movieHandler.CustomMethod)
+		api.GET("/movies/:movieId/similar", movieHandler.GetSimilarMovies)
+
+		// Rating routes.
+		api.GET("/movies/:movieId/rating", middleware.RequireUser(), ratingHandler.GetRating)
+		api.PUT("/movies/:movieId/rating", middleware.RequireUser(), ratingHandler.SetRating)
+		api.DELETE("/movies/:movieId/rating", middleware.RequireUser(), ratingHandler.DeleteRating)
 
 		// Comment routes.
 		api.GET("/movies/:movieId/comments/:commentId", commentHandler.GetMovieComment)
 		api.GET("/movies/:movieId/comments", commentHandler.GetMovieComments)
+		api.GET("/movies/:movieId/comments/:commentId/replies", commentHandler.GetCommentReplies)
 		api.POST("/movies/:movieId/comments", commentHandler.CreateComment)
 		api.PUT("/movies/:movieId/comments/:commentId", commentHandler.UpdateComment)
 		api.DELETE("/movies/:movieId/comments/:commentId", commentHandler.DeleteComment)
+
+		// Comment reaction routes.
+		api.POST("/movies/:movieId/comments/:commentId/reactions", middleware.RequireUser(), commentHandler.AddReaction)
+		api.DELETE("/movies/:movieId/comments/:commentId/reactions", middleware.RequireUser(), commentHandler.RemoveReaction)
+
+		// Collection routes.
+		api.GET("/collections", collectionHandler.ListPublicCollections)
+		api.GET("/collections/:collectionId", collectionHandler.GetCollection)
+		api.POST("/collections", middleware.RequireUser(), collectionHandler.CreateCollection)
+		api.PUT("/collections/:collectionId", middleware.RequireUser(), collectionHandler.UpdateCollection)
+		api.DELETE("/collections/:collectionId", middleware.RequireUser(), collectionHandler.DeleteCollection)
+
+		// People routes.
+		api.GET("/people", personHandler.SearchPeople)
+		api.GET("/people/:name/movies", personHandler.GetPersonMovies)
+
+		// Reference routes.
+		for _, kind := range domain.ReferenceKinds {
+			api.GET("/"+string(kind), referenceHandler.GetValues(kind))
+		}
+
+		// Stats routes.
+		stats := api.Group("/stats")
+		stats.GET("/movies-per-year", statsHandler.MoviesPerYear)
+		stats.GET("/genres", statsHandler.GenreDistribution)
+		stats.GET("/ratings/genre-decade", statsHandler.RatingsByGenreDecade)
+		stats.GET("/directors/top", statsHandler.TopDirectors)
+		stats.GET("/comments/activity", statsHandler.CommentActivity)
+
+		// Current user routes.
+		me := api.Group("/me", middleware.RequireUser())
+		me.GET("/collections", collectionHandler.ListUserCollections)
+		for _, list := range []domain.UserListKind{domain.Watchlist, domain.Favorites} {
+			path := "/" + string(list)
+			me.GET(path, userListHandler.GetList(list))
+			me.POST(path, userListHandler.AddMovie(list))
+			me.PUT(path+"/order", userListHandler.Reorder(list))
+			me.DELETE(path+"/:movieId", userListHandler.RemoveMovie(list))
+		}
+
+		// Moderation routes.
+		api.POST("/movies/:movieId/comments/:commentId/flags", middleware.RequireUser(), moderationHandler.FlagComment)
+		moderation := api.Group("/moderation", middleware.RequireUser())
+		moderation.GET("/queue", moderationHandler.GetQueue)
+		moderation.POST("/comments/:commentId/approve", moderationHandler.ApproveComment)
+		moderation.POST("/comments/:commentId/reject", moderationHandler.RejectComment)
 	}
 }
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	MaxCollectionTitleLength       = 200
+	MaxCollectionDescriptionLength = 2000
+	MaxCollectionMovies            = 500
+)
+
+var (
+	ErrCollectionNotFound           = errors.New("collection not found")
+	ErrNotCollectionOwner           = errors.New("user does not own collection")
+	ErrInvalidCollectionTitle       = errors.New("collection title must be between 1 and 200 characters")
+	ErrInvalidCollectionDescription = errors.New("collection description must be at most 2000 characters")
+	ErrInvalidCollectionVisibility  = errors.New("collection visibility must be private, unlisted or public")
+	ErrInvalidCollectionMovies      = errors.New("collection must contain at most 500 distinct movies")
+)
+
+// CollectionVisibility controls who can see a collection. Unlisted
+// collections can be viewed by anyone with the ID but are not listed.
+type CollectionVisibility string
+
+const (
+	CollectionPrivate  CollectionVisibility = "private"
+	CollectionUnlisted CollectionVisibility = "unlisted"
+	CollectionPublic   CollectionVisibility = "public"
+)
+
+// Collection is a curated, ordered list of movies.
+type Collection struct {
+	ID          primitive.ObjectID   `bson:"_id" json:"id"`
+	Title       string               `bson:"title" json:"title"`
+	Description string               `bson:"description" json:"description"`
+	MovieIDs    []primitive.ObjectID `bson:"movie_ids" json:"movie_ids"`
+	Owner       string               `bson:"owner" json:"owner"`
+	Visibility  CollectionVisibility `bson:"visibility" json:"visibility"`
+	CreatedAt   primitive.DateTime   `bson:"created_at" json:"created_at"`
+	UpdatedAt   primitive.DateTime   `bson:"updated_at" json:"updated_at"`
+}
+
+// VisibleTo reports whether userID may view the collection.
+func (c *Collection) VisibleTo(userID string) bool {
+	return c.Visibility != CollectionPrivate || c.Owner == userID
+}
+
+// CollectionWithMovies is a collection with its movies resolved, in
+// collection order. Movies that no longer exist are left out.
+type CollectionWithMovies struct {
+	Collection
+	Movies []MovieSummary `json:"movies"`
+}
+
+type CollectionRepository interface {
+	Create(ctx context.Context, collection *Collection) error
+	Get(ctx context.Context, id primitive.ObjectID) (*Collection, error)
+	Update(ctx context.Context, collection *Collection) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	ListPublic(ctx context.Context, page, limit int) ([]Collection, error)
+	ListByOwner(ctx context.Context, owner string, page, limit int) ([]Collection, error)
+}
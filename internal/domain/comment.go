@@ -3,19 +3,67 @@ package domain
 import (
 	"context"
 	"errors"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-var ErrCommentNotFoundForMovie = errors.New("comment not found for movie")
+var (
+	ErrCommentNotFoundForMovie = errors.New("comment not found for movie")
+	ErrParentCommentNotFound   = errors.New("parent comment not found for movie")
+	ErrInvalidCommentView      = errors.New("invalid comment view")
+	ErrInvalidCommentSort      = errors.New("invalid comment sort")
+	ErrCommentRejected         = errors.New("comment rejected")
+	ErrCommentNotEditable      = errors.New("comment hidden or rejected by moderation cannot be edited")
+)
 
 type Comment struct {
-	ID      primitive.ObjectID `bson:"_id" json:"id"`
-	MovieID primitive.ObjectID `bson:"movie_id" json:"movie_id"`
-	Name    string             `bson:"name" json:"name"`
-	Email   string             `bson:"email" json:"email"`
-	Text    string             `bson:"text" json:"text"`
-	Date    primitive.DateTime `bson:"date" json:"date"`
+	ID         primitive.ObjectID  `bson:"_id" json:"id"`
+	MovieID    primitive.ObjectID  `bson:"movie_id" json:"movie_id"`
+	ParentID   *primitive.ObjectID `bson:"parent_id,omitempty" json:"parent_id,omitempty"`
+	Name       string              `bson:"name" json:"name"`
+	Email      string              `bson:"email" json:"email"`
+	Text       string              `bson:"text" json:"text"`
+	Date       primitive.DateTime  `bson:"date" json:"date"`
+	ReplyCount int                 `bson:"reply_count" json:"reply_count"`
+
+	// Reactions holds the number of reactions of each type, with
+	// ReactionCount their total.
+	Reactions     map[ReactionType]int `bson:"reactions,omitempty" json:"reactions,omitempty"`
+	ReactionCount int                  `bson:"reaction_count" json:"reaction_count"`
+
+	Status     CommentStatus       `bson:"status,omitempty" json:"status,omitempty"`
+	FlagCount  int                 `bson:"flag_count,omitempty" json:"flag_count,omitempty"`
+	Moderation *ModerationDecision `bson:"moderation,omitempty" json:"moderation,omitempty"`
+}
+
+// CommentSort is the order comments are listed in.
+type CommentSort string
+
+const (
+	// CommentSortDefault lists comments in the order they are stored.
+	CommentSortDefault CommentSort = ""
+	// CommentSortTop lists the most reacted-to comments first.
+	CommentSortTop CommentSort = "top"
+)
+
+// CommentView controls how a movie's comments are arranged when returned
+// as a thread.
+type CommentView string
+
+const (
+	// CommentViewTree nests replies under their parent comment.
+	CommentViewTree CommentView = "tree"
+	// CommentViewFlat returns comments in thread order with their depth.
+	CommentViewFlat CommentView = "flat"
+)
+
+// CommentNode is a comment positioned within a thread. Replies is only
+// populated for the tree view.
+type CommentNode struct {
+	Comment
+	Depth   int           `json:"depth"`
+	Replies []CommentNode `json:"replies,omitempty"`
 }
 
 type CommentRepository interface {
@@ -23,5 +71,9 @@ type CommentRepository interface {
 	Update(ctx context.Context, comment *Comment) error
 	Delete(ctx context.Context, movieID, commentID primitive.ObjectID) error
 	GetMovieComment(ctx context.Context, movieID, commentID primitive.ObjectID) (*Comment, error)
-	GetMovieComments(ctx context.Context, movieID primitive.ObjectID) ([]Comment, error)
+	GetMovieComments(ctx context.Context, movieID primitive.ObjectID, sort CommentSort) ([]Comment, error)
+	GetCommentReplies(ctx context.Context, movieID, parentID primitive.ObjectID) ([]Comment, error)
+	// CountDuplicates counts other comments with the same email and text
+	// posted since the given time.
+	CountDuplicates(ctx context.Context, comment *Comment, since time.Time) (int64, error)
 }
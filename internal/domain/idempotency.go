@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidIdempotencyKey    = errors.New("invalid idempotency key")
+	ErrIdempotencyKeyReused     = errors.New("idempotency key reused with a different request")
+	ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is in progress")
+	ErrIdempotencyKeyExists     = errors.New("idempotency key already exists")
+)
+
+// IdempotentRequest records a request made with an idempotency key so
+// retries can be answered with the original response instead of repeating
+// the request. Status is zero until the original request completes.
+type IdempotentRequest struct {
+	// ID identifies the key within the scope it was used in.
+	ID          string    `bson:"_id"`
+	RequestHash string    `bson:"request_hash"`
+	Status      int       `bson:"status"`
+	ContentType string    `bson:"content_type,omitempty"`
+	Body        []byte    `bson:"body,omitempty"`
+	CreatedAt   time.Time `bson:"created_at"`
+	// ExpiresAt is when the record is removed and the key can be reused.
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+// Completed reports whether the original request has a stored response.
+func (r *IdempotentRequest) Completed() bool {
+	return r.Status != 0
+}
+
+type IdempotencyRepository interface {
+	// Create stores a new record, returning ErrIdempotencyKeyExists if one
+	// with the same ID exists.
+	Create(ctx context.Context, request *IdempotentRequest) error
+	Get(ctx context.Context, id string) (*IdempotentRequest, error)
+	// Complete stores the response to the request and when it expires.
+	Complete(ctx context.Context, id string, status int, contentType string, body []byte, expiresAt time.Time) error
+	// Delete removes the record if it exists.
+	Delete(ctx context.Context, id string) error
+	// DeleteExpired removes the record if it exists and expired at or
+	// before now, leaving one that has since been replaced or completed.
+	DeleteExpired(ctx context.Context, id string, now time.Time) error
+}
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrCommentNotFound   = errors.New("comment not found")
+	ErrAlreadyFlagged    = errors.New("comment already flagged by user")
+	ErrNotModerator      = errors.New("user is not a moderator")
+	ErrInvalidFlagReason = errors.New("flag reason must be between 1 and 500 characters")
+	ErrCommentNotInQueue = errors.New("comment is not awaiting moderation")
+)
+
+// CommentStatus is the moderation state of a comment. Comments that have
+// never been flagged have no status.
+type CommentStatus string
+
+const (
+	CommentStatusVisible CommentStatus = ""
+	// CommentStatusPending comments were held by a content filter and are
+	// hidden until reviewed.
+	CommentStatusPending CommentStatus = "pending"
+	// CommentStatusFlagged comments are visible but awaiting review.
+	CommentStatusFlagged CommentStatus = "flagged"
+	// CommentStatusHidden comments reached the flag threshold and are hidden
+	// until reviewed.
+	CommentStatusHidden CommentStatus = "hidden"
+	// CommentStatusApproved comments were reviewed and kept. Further flags
+	// are recorded but do not hide them again.
+	CommentStatusApproved CommentStatus = "approved"
+	// CommentStatusRejected comments were reviewed and removed from view.
+	CommentStatusRejected CommentStatus = "rejected"
+)
+
+// Flag is a user's report that a comment is abusive.
+type Flag struct {
+	ID        primitive.ObjectID `bson:"_id" json:"id"`
+	CommentID primitive.ObjectID `bson:"comment_id" json:"comment_id"`
+	MovieID   primitive.ObjectID `bson:"movie_id" json:"movie_id"`
+	UserID    string             `bson:"user_id" json:"user_id"`
+	Reason    string             `bson:"reason" json:"reason"`
+	Date      primitive.DateTime `bson:"date" json:"date"`
+}
+
+// ModerationDecision records a moderator's review of a comment.
+type ModerationDecision struct {
+	Moderator string             `bson:"moderator" json:"moderator"`
+	Status    CommentStatus      `bson:"status" json:"status"`
+	Note      string             `bson:"note,omitempty" json:"note,omitempty"`
+	Date      primitive.DateTime `bson:"date" json:"date"`
+}
+
+type FlagRepository interface {
+	// Create stores the flag, returning ErrAlreadyFlagged if the user has
+	// already flagged the comment.
+	Create(ctx context.Context, flag *Flag) error
+}
+
+type ModerationRepository interface {
+	// AddFlag increments the comment's flag count and marks it flagged, or
+	// hidden once the count reaches threshold.
+	AddFlag(ctx context.Context, movieID, commentID primitive.ObjectID, threshold int) error
+	// GetQueue lists comments awaiting review across all movies, most
+	// flagged first.
+	GetQueue(ctx context.Context, page, limit int) ([]Comment, error)
+	// Decide applies a moderator's decision to a comment awaiting review,
+	// returning ErrCommentNotInQueue if it is not.
+	Decide(ctx context.Context, commentID primitive.ObjectID, decision *ModerationDecision) (*Comment, error)
+}
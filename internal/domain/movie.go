@@ -2,10 +2,23 @@ package domain
 
 import (
 	"context"
+	"errors"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// MaxMovieBatchSize caps the number of movies fetched in one batch.
+const MaxMovieBatchSize = 100
+
+var (
+	ErrMovieNotFound    = errors.New("movie not found")
+	ErrInvalidMovieSort = errors.New("invalid movie sort")
+	ErrInvalidLimit     = errors.New("limit out of range")
+	ErrInvalidPrefix    = errors.New("prefix must not be empty")
+	ErrInvalidFacet     = errors.New("invalid movie facet")
+	ErrInvalidBatchSize = errors.New("batch must contain between 1 and 100 ids")
+)
+
 type Movie struct {
 	ID               primitive.ObjectID `bson:"_id" json:"_id"`
 	Plot             string             `bson:"plot" json:"plot"`
@@ -27,6 +40,7 @@ type Movie struct {
 	Type             string             `bson:"type" json:"type"`
 	Tomatoes         Tomatoes           `bson:"tomatoes" json:"tomatoes"`
 	Poster           string             `bson:"poster" json:"poster"`
+	CommunityRating  *CommunityRating   `bson:"community_rating,omitempty" json:"community_rating,omitempty"`
 }
 
 type Awards struct {
@@ -56,7 +70,132 @@ type Viewer struct {
 	Meter      int     `bson:"meter" json:"meter"`
 }
 
+// MovieSummary is the subset of a movie's fields shown when movies are
+// listed alongside other resources.
+type MovieSummary struct {
+	ID      primitive.ObjectID `bson:"_id" json:"_id"`
+	Title   string             `bson:"title" json:"title"`
+	Year    int                `bson:"year" json:"year"`
+	Genres  []string           `bson:"genres" json:"genres"`
+	Runtime int                `bson:"runtime" json:"runtime"`
+	Rated   string             `bson:"rated" json:"rated"`
+	IMDB    IMDB               `bson:"imdb" json:"imdb"`
+	Poster  string             `bson:"poster" json:"poster"`
+}
+
+// Summary returns the movie's summary fields.
+func (m *Movie) Summary() MovieSummary {
+	return MovieSummary{
+		ID:      m.ID,
+		Title:   m.Title,
+		Year:    m.Year,
+		Genres:  m.Genres,
+		Runtime: m.Runtime,
+		Rated:   m.Rated,
+		IMDB:    m.IMDB,
+		Poster:  m.Poster,
+	}
+}
+
+// MovieSuggestion is the minimal view of a movie returned while a user is
+// typing a title.
+type MovieSuggestion struct {
+	ID     primitive.ObjectID `bson:"_id" json:"_id"`
+	Title  string             `bson:"title" json:"title"`
+	Year   int                `bson:"year" json:"year"`
+	Poster string             `bson:"poster,omitempty" json:"poster,omitempty"`
+}
+
+// SimilarMovie is a movie recommended for being like another, with the
+// score it was ranked by.
+type SimilarMovie struct {
+	MovieSummary
+	Score float64 `json:"score"`
+}
+
+// MovieBatch is the result of fetching several movies by ID. Movies are in
+// the order they were requested, with the IDs that do not exist listed
+// separately.
+type MovieBatch struct {
+	Movies   []Movie              `json:"movies"`
+	NotFound []primitive.ObjectID `json:"not_found"`
+}
+
+// MovieSort is the order movies are listed in.
+type MovieSort string
+
+const (
+	// MovieSortDefault lists movies in the order they are stored.
+	MovieSortDefault MovieSort = ""
+	// MovieSortCommunityRating lists the highest community rated movies
+	// first.
+	MovieSortCommunityRating MovieSort = "community_rating"
+)
+
+// MovieQuery filters, orders and pages a movie listing. Zero values apply no
+// filter.
+type MovieQuery struct {
+	Title              string
+	MinCommunityRating float64
+	MinCommunityVotes  int
+	Sort               MovieSort
+	Page               int
+	Limit              int
+	// Fields limits the fields loaded to these BSON paths, loading every
+	// field when empty.
+	Fields []string
+}
+
+// MovieFacet is a field movies can be counted by alongside a listing.
+type MovieFacet string
+
+const (
+	MovieFacetGenres    MovieFacet = "genres"
+	MovieFacetDecade    MovieFacet = "decade"
+	MovieFacetRated     MovieFacet = "rated"
+	MovieFacetLanguages MovieFacet = "languages"
+	MovieFacetCountries MovieFacet = "countries"
+)
+
+// Valid reports whether f is a known facet.
+func (f MovieFacet) Valid() bool {
+	switch f {
+	case MovieFacetGenres, MovieFacetDecade, MovieFacetRated, MovieFacetLanguages, MovieFacetCountries:
+		return true
+	}
+	return false
+}
+
+// FacetCount is the number of movies having a value of a facet.
+type FacetCount struct {
+	Value string `bson:"_id" json:"value"`
+	Count int    `bson:"count" json:"count"`
+}
+
+// MovieFacets holds the counts for each requested facet.
+type MovieFacets map[MovieFacet][]FacetCount
+
 type MovieRepository interface {
-	GetMovie(ctx context.Context, id primitive.ObjectID) (*Movie, error)
-	GetMovies(ctx context.Context, titleFilter string, page, limit int) ([]Movie, error)
+	// GetMovie returns the movie with the given ID, loading only fields if
+	// any are given.
+	GetMovie(ctx context.Context, id primitive.ObjectID, fields []string) (*Movie, error)
+	GetMovies(ctx context.Context, query MovieQuery) ([]Movie, error)
+	// GetMovieFacets counts the movies matching query's filter by each of
+	// the given facets. Paging and sorting are ignored.
+	GetMovieFacets(ctx context.Context, query MovieQuery, facets []MovieFacet) (MovieFacets, error)
+	// GetMovieSummaries returns summaries of the movies with the given IDs
+	// in a single query. Movies that do not exist are left out and the
+	// order is not preserved.
+	GetMovieSummaries(ctx context.Context, ids []primitive.ObjectID) ([]MovieSummary, error)
+	// GetMoviesByIDs returns the movies with the given IDs in a single query,
+	// loading only fields if any are given. Movies that do not exist are
+	// left out and the order is not preserved.
+	GetMoviesByIDs(ctx context.Context, ids []primitive.ObjectID, fields []string) ([]Movie, error)
+	// GetSimilarCandidates returns up to limit movies sharing a genre,
+	// director or cast member with movie, those with the most in common
+	// first.
+	GetSimilarCandidates(ctx context.Context, movie *Movie, limit int) ([]Movie, error)
+	// Autocomplete returns up to limit movies whose normalized title starts
+	// with prefix, which must already be normalized.
+	Autocomplete(ctx context.Context, prefix string, limit int) ([]MovieSuggestion, error)
 }
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrPersonNotFound    = errors.New("person not found")
+	ErrInvalidPersonName = errors.New("name must not be empty")
+	ErrInvalidPersonSort = errors.New("invalid person movie sort")
+)
+
+// PersonRole is the part a person played in making a movie.
+type PersonRole string
+
+const (
+	PersonRoleDirector PersonRole = "director"
+	PersonRoleCast     PersonRole = "cast"
+)
+
+// Person is someone who directed or appeared in at least one movie, with
+// stats over all of their movies. People are identified by their
+// normalized name.
+type Person struct {
+	Name          string       `bson:"name" json:"name"`
+	Roles         []PersonRole `bson:"roles" json:"roles"`
+	Movies        int          `bson:"movies" json:"movies"`
+	FirstYear     int          `bson:"first_year,omitempty" json:"first_year,omitempty"`
+	LastYear      int          `bson:"last_year,omitempty" json:"last_year,omitempty"`
+	AverageRating float64      `bson:"average_rating,omitempty" json:"average_rating,omitempty"`
+}
+
+// PersonMovie is a movie in a person's filmography, with the roles they
+// had in it.
+type PersonMovie struct {
+	MovieSummary `bson:",inline"`
+	Roles        []PersonRole `bson:"roles" json:"roles"`
+}
+
+// Filmography is a person and a page of their movies.
+type Filmography struct {
+	Person Person        `json:"person"`
+	Movies []PersonMovie `json:"movies"`
+}
+
+// PersonMovieSort is the order a person's movies are listed in.
+type PersonMovieSort string
+
+const (
+	// PersonMovieSortYear lists the earliest movies first.
+	PersonMovieSortYear PersonMovieSort = "year"
+	// PersonMovieSortRating lists the highest IMDB rated movies first.
+	PersonMovieSortRating PersonMovieSort = "rating"
+)
+
+type PersonRepository interface {
+	// SearchPeople returns up to limit people with a word in their
+	// normalized name starting with query, which must already be
+	// normalized. Those with the most movies are returned first.
+	SearchPeople(ctx context.Context, query string, limit int) ([]Person, error)
+	// GetPerson returns the person with the given normalized name.
+	GetPerson(ctx context.Context, name string) (*Person, error)
+	// GetPersonMovies returns a page of the movies of the person with the
+	// given normalized name.
+	GetPersonMovies(ctx context.Context, name string, sort PersonMovieSort, page, limit int) ([]PersonMovie, error)
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	MinRatingScore = 1
+	MaxRatingScore = 10
+)
+
+var (
+	ErrInvalidRatingScore = errors.New("rating score must be between 1 and 10")
+	ErrRatingNotFound     = errors.New("rating not found")
+)
+
+// Rating is a user's score for a movie. Each user has at most one rating per
+// movie.
+type Rating struct {
+	ID      primitive.ObjectID `bson:"_id" json:"id"`
+	MovieID primitive.ObjectID `bson:"movie_id" json:"movie_id"`
+	UserID  string             `bson:"user_id" json:"user_id"`
+	Score   int                `bson:"score" json:"score"`
+	Date    primitive.DateTime `bson:"date" json:"date"`
+}
+
+// CommunityRating aggregates user ratings for a movie. Histogram maps each
+// score to the number of users who gave it.
+type CommunityRating struct {
+	Average   float64        `bson:"average" json:"average"`
+	Count     int            `bson:"count" json:"count"`
+	Histogram map[string]int `bson:"histogram" json:"histogram"`
+}
+
+type RatingRepository interface {
+	// Set creates or replaces the user's rating for the movie.
+	Set(ctx context.Context, rating *Rating) error
+	Get(ctx context.Context, movieID primitive.ObjectID, userID string) (*Rating, error)
+	Delete(ctx context.Context, movieID primitive.ObjectID, userID string) error
+	// RefreshCommunityRating recalculates the movie's community rating from
+	// its ratings.
+	RefreshCommunityRating(ctx context.Context, movieID primitive.ObjectID) error
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrInvalidReactionType = errors.New("invalid reaction type")
+
+type ReactionType string
+
+const (
+	ReactionLike       ReactionType = "like"
+	ReactionLove       ReactionType = "love"
+	ReactionFunny      ReactionType = "funny"
+	ReactionInsightful ReactionType = "insightful"
+)
+
+// Valid reports whether t is one of the supported reaction types.
+func (t ReactionType) Valid() bool {
+	switch t {
+	case ReactionLike, ReactionLove, ReactionFunny, ReactionInsightful:
+		return true
+	}
+	return false
+}
+
+// Reaction is a single user's reaction to a comment. A user holds at most
+// one reaction per comment; reacting again replaces the previous type.
+type Reaction struct {
+	ID        primitive.ObjectID `bson:"_id" json:"id"`
+	CommentID primitive.ObjectID `bson:"comment_id" json:"comment_id"`
+	MovieID   primitive.ObjectID `bson:"movie_id" json:"movie_id"`
+	UserID    string             `bson:"user_id" json:"user_id"`
+	Type      ReactionType       `bson:"type" json:"type"`
+	Date      primitive.DateTime `bson:"date" json:"date"`
+}
+
+type ReactionRepository interface {
+	// Set records the user's reaction, replacing any previous one, and keeps
+	// the comment's reaction counts up to date.
+	Set(ctx context.Context, reaction *Reaction) error
+	// Remove deletes the user's reaction if there is one.
+	Remove(ctx context.Context, commentID primitive.ObjectID, userID string) error
+}
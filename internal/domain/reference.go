@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrInvalidReferenceKind = errors.New("invalid reference kind")
+
+// ReferenceKind is a movie field whose distinct values clients can list,
+// e.g. to build filters.
+type ReferenceKind string
+
+const (
+	ReferenceGenres    ReferenceKind = "genres"
+	ReferenceCountries ReferenceKind = "countries"
+	ReferenceLanguages ReferenceKind = "languages"
+	// ReferenceRatings lists the audience ratings movies are rated with,
+	// such as PG-13.
+	ReferenceRatings ReferenceKind = "ratings"
+)
+
+// ReferenceKinds lists every reference kind.
+var ReferenceKinds = []ReferenceKind{
+	ReferenceGenres,
+	ReferenceCountries,
+	ReferenceLanguages,
+	ReferenceRatings,
+}
+
+// Valid reports whether k is a known reference kind.
+func (k ReferenceKind) Valid() bool {
+	for _, kind := range ReferenceKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// ReferenceValue is a distinct value of a movie field and the number of
+// movies having it.
+type ReferenceValue struct {
+	Value string `bson:"_id" json:"value"`
+	Count int    `bson:"count" json:"count"`
+}
+
+type ReferenceRepository interface {
+	// GetReferenceValues returns every value of kind, the most common
+	// first.
+	GetReferenceValues(ctx context.Context, kind ReferenceKind) ([]ReferenceValue, error)
+}
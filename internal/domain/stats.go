@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidStatsInterval = errors.New("invalid stats interval")
+	ErrInvalidStatsRange    = errors.New("from must be before to")
+	ErrInvalidMinVotes      = errors.New("min_votes must not be negative")
+)
+
+// StatsInterval is the period activity is grouped by.
+type StatsInterval string
+
+const (
+	StatsIntervalDay   StatsInterval = "day"
+	StatsIntervalWeek  StatsInterval = "week"
+	StatsIntervalMonth StatsInterval = "month"
+	StatsIntervalYear  StatsInterval = "year"
+)
+
+// Valid reports whether i is a known interval.
+func (i StatsInterval) Valid() bool {
+	switch i {
+	case StatsIntervalDay, StatsIntervalWeek, StatsIntervalMonth, StatsIntervalYear:
+		return true
+	}
+	return false
+}
+
+// YearCount is the number of movies released in a year.
+type YearCount struct {
+	Year  int `bson:"_id" json:"year"`
+	Count int `bson:"count" json:"count"`
+}
+
+// GenreCount is the number of movies in a genre.
+type GenreCount struct {
+	Genre string `bson:"_id" json:"genre"`
+	Count int    `bson:"count" json:"count"`
+}
+
+// GenreDecadeRating is the average IMDB rating of a genre's movies released
+// in a decade.
+type GenreDecadeRating struct {
+	Genre         string  `bson:"genre" json:"genre"`
+	Decade        int     `bson:"decade" json:"decade"`
+	AverageRating float64 `bson:"average_rating" json:"average_rating"`
+	Movies        int     `bson:"movies" json:"movies"`
+}
+
+// DirectorRating is the average IMDB rating of a director's movies.
+type DirectorRating struct {
+	Director      string  `bson:"_id" json:"director"`
+	AverageRating float64 `bson:"average_rating" json:"average_rating"`
+	Movies        int     `bson:"movies" json:"movies"`
+	Votes         int     `bson:"votes" json:"votes"`
+}
+
+// CommentActivity is the number of comments posted in the period starting
+// at Period.
+type CommentActivity struct {
+	Period time.Time `bson:"_id" json:"period"`
+	Count  int       `bson:"count" json:"count"`
+}
+
+// TopDirectorsQuery selects the directors ranked by TopDirectors. Only
+// directors whose movies have at least MinVotes IMDB votes in total are
+// ranked.
+type TopDirectorsQuery struct {
+	MinVotes int
+	Limit    int
+}
+
+// CommentActivityQuery selects the comments counted by CommentActivity.
+// Zero times leave the range open.
+type CommentActivityQuery struct {
+	Interval StatsInterval
+	From     time.Time
+	To       time.Time
+}
+
+type StatsRepository interface {
+	MoviesPerYear(ctx context.Context) ([]YearCount, error)
+	GenreDistribution(ctx context.Context) ([]GenreCount, error)
+	RatingsByGenreDecade(ctx context.Context) ([]GenreDecadeRating, error)
+	TopDirectors(ctx context.Context, query TopDirectorsQuery) ([]DirectorRating, error)
+	CommentActivity(ctx context.Context, query CommentActivityQuery) ([]CommentActivity, error)
+}
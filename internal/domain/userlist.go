@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrMovieAlreadyInList = errors.New("movie already in list")
+	ErrMovieNotInList     = errors.New("movie not in list")
+	ErrInvalidListOrder   = errors.New("order must contain every movie in the list exactly once")
+)
+
+// UserListKind names one of the per-user movie lists.
+type UserListKind string
+
+const (
+	Watchlist UserListKind = "watchlist"
+	Favorites UserListKind = "favorites"
+)
+
+// UserListEntry is a movie saved to one of a user's lists. Entries are
+// ordered by Position, which new entries take from the end of the list.
+type UserListEntry struct {
+	ID       primitive.ObjectID `bson:"_id" json:"-"`
+	UserID   string             `bson:"user_id" json:"-"`
+	List     UserListKind       `bson:"list" json:"-"`
+	MovieID  primitive.ObjectID `bson:"movie_id" json:"movie_id"`
+	Position int                `bson:"position" json:"position"`
+	AddedAt  primitive.DateTime `bson:"added_at" json:"added_at"`
+}
+
+// UserListItem is a list entry together with a summary of its movie. Movie
+// is nil if the movie no longer exists.
+type UserListItem struct {
+	UserListEntry
+	Movie *MovieSummary `json:"movie"`
+}
+
+type UserListRepository interface {
+	// Add appends the entry to the end of its list, returning
+	// ErrMovieAlreadyInList if the movie is already in it.
+	Add(ctx context.Context, entry *UserListEntry) error
+	Remove(ctx context.Context, userID string, list UserListKind, movieID primitive.ObjectID) error
+	List(ctx context.Context, userID string, list UserListKind, page, limit int) ([]UserListEntry, error)
+	// Reorder sets the order of the whole list. movieIDs must contain every
+	// movie in the list exactly once.
+	Reorder(ctx context.Context, userID string, list UserListKind, movieIDs []primitive.ObjectID) error
+}
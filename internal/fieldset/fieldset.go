@@ -0,0 +1,209 @@
+// Package fieldset lets clients select the subset of a resource's fields
+// they need, both when loading it from Mongo and when encoding it as JSON.
+package fieldset
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+var ErrUnknownField = errors.New("unknown field")
+
+// idField is the BSON path of the document ID, which is always included in
+// a selection so results can be told apart.
+const idField = "_id"
+
+// Whitelist maps the JSON path of each field that may be selected to its
+// BSON path. Nested fields use dotted paths, e.g. "imdb.rating".
+type Whitelist map[string]string
+
+// New builds the whitelist for v's type from its json and bson struct tags,
+// descending into nested structs.
+func New(v any) Whitelist {
+	w := Whitelist{}
+	w.add(reflect.TypeOf(v), "", "")
+	return w
+}
+
+func (w Whitelist) add(t reflect.Type, jsonPrefix, bsonPrefix string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == reflect.TypeOf(time.Time{}) {
+		return
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		jsonName := tagName(field, "json")
+		bsonName := tagName(field, "bson")
+		if jsonName == "-" || bsonName == "-" {
+			continue
+		}
+
+		// Untagged embedded structs are inlined by both encoders, even when
+		// the embedded type is unexported.
+		if field.Anonymous && jsonName == "" {
+			w.add(field.Type, jsonPrefix, bsonPrefix)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		if jsonName == "" {
+			jsonName = field.Name
+		}
+		if bsonName == "" {
+			bsonName = strings.ToLower(field.Name)
+		}
+
+		jsonPath := jsonPrefix + jsonName
+		bsonPath := bsonPrefix + bsonName
+		w[jsonPath] = bsonPath
+		w.add(field.Type, jsonPath+".", bsonPath+".")
+	}
+}
+
+func tagName(field reflect.StructField, key string) string {
+	name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+	return name
+}
+
+// Parse validates a comma separated list of JSON paths. It returns nil if
+// value selects nothing, meaning every field should be returned.
+func (w Whitelist) Parse(value string) (*Selection, error) {
+	selected := map[string]bool{}
+	for _, path := range strings.Split(value, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		if _, ok := w[path]; !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownField, path)
+		}
+		selected[path] = true
+	}
+	if len(selected) == 0 {
+		return nil, nil
+	}
+	for path, bsonPath := range w {
+		if bsonPath == idField {
+			selected[path] = true
+		}
+	}
+
+	s := &Selection{tree: fieldTree{}}
+	for path := range selected {
+		// Selecting a field already selects everything under it, and Mongo
+		// rejects projections naming both.
+		if hasSelectedAncestor(path, selected) {
+			continue
+		}
+		s.projection = append(s.projection, w[path])
+		s.tree.add(strings.Split(path, "."))
+	}
+	sort.Strings(s.projection)
+
+	return s, nil
+}
+
+func hasSelectedAncestor(path string, selected map[string]bool) bool {
+	for i := strings.LastIndex(path, "."); i >= 0; i = strings.LastIndex(path, ".") {
+		path = path[:i]
+		if selected[path] {
+			return true
+		}
+	}
+	return false
+}
+
+// Selection is a validated set of fields. A nil Selection selects every
+// field.
+type Selection struct {
+	projection []string
+	tree       fieldTree
+}
+
+// Projection returns the BSON paths of the selected fields, or nil if every
+// field is selected.
+func (s *Selection) Projection() []string {
+	if s == nil {
+		return nil
+	}
+	return s.projection
+}
+
+// Apply returns v's JSON encoding limited to the selected fields. If v is a
+// slice each element is limited.
+func (s *Selection) Apply(v any) (any, error) {
+	if s == nil {
+		return v, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	// Numbers are kept as written rather than converted to float64.
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var decoded any
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, err
+	}
+
+	return s.tree.pick(decoded), nil
+}
+
+// fieldTree holds the selected paths by segment. A nil subtree selects the
+// whole value.
+type fieldTree map[string]fieldTree
+
+func (t fieldTree) add(segments []string) {
+	if len(segments) == 1 {
+		t[segments[0]] = nil
+		return
+	}
+
+	sub, ok := t[segments[0]]
+	if !ok {
+		sub = fieldTree{}
+		t[segments[0]] = sub
+	}
+	sub.add(segments[1:])
+}
+
+func (t fieldTree) pick(v any) any {
+	switch v := v.(type) {
+	case []any:
+		for i := range v {
+			v[i] = t.pick(v[i])
+		}
+		return v
+	case map[string]any:
+		picked := make(map[string]any, len(t))
+		for key, sub := range t {
+			value, ok := v[key]
+			if !ok {
+				continue
+			}
+			if sub == nil {
+				picked[key] = value
+			} else {
+				picked[key] = sub.pick(value)
+			}
+		}
+		return picked
+	}
+
+	return v
+}
@@ -0,0 +1,107 @@
+package fieldset
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type rating struct {
+	Score float64 `bson:"score" json:"score"`
+	Votes int     `bson:"votes" json:"votes"`
+}
+
+type embedded struct {
+	Note string `bson:"note" json:"note"`
+}
+
+type item struct {
+	embedded
+	ID       string   `bson:"_id" json:"id"`
+	Title    string   `bson:"title" json:"title"`
+	Tags     []string `bson:"tags" json:"tags"`
+	Rating   rating   `bson:"rating_info" json:"rating"`
+	Optional *rating  `bson:"optional,omitempty" json:"optional,omitempty"`
+	Secret   string   `bson:"secret" json:"-"`
+}
+
+func TestNew(t *testing.T) {
+	expected := Whitelist{
+		"note":           "note",
+		"id":             "_id",
+		"title":          "title",
+		"tags":           "tags",
+		"rating":         "rating_info",
+		"rating.score":   "rating_info.score",
+		"rating.votes":   "rating_info.votes",
+		"optional":       "optional",
+		"optional.score": "optional.score",
+		"optional.votes": "optional.votes",
+	}
+
+	assert.Equal(t, expected, New(item{}))
+}
+
+func TestParse(t *testing.T) {
+	w := Whitelist{
+		"_id":          "_id",
+		"title":        "title",
+		"imdb":         "imdb",
+		"imdb.rating":  "imdb.rating",
+		"imdb.votes":   "imdb.votes",
+		"display_name": "name",
+	}
+
+	tests := map[string]struct {
+		input      string
+		projection []string
+		err        error
+	}{
+		"Empty selects everything": {input: "", projection: nil},
+		"Only blanks":              {input: " , ,", projection: nil},
+		"Single field":             {input: "title", projection: []string{"_id", "title"}},
+		"Nested field":             {input: "title, imdb.rating", projection: []string{"_id", "imdb.rating", "title"}},
+		"Renamed field":            {input: "display_name", projection: []string{"_id", "name"}},
+		"Parent and child":         {input: "imdb.votes,imdb", projection: []string{"_id", "imdb"}},
+		"Duplicates":               {input: "title,title", projection: []string{"_id", "title"}},
+		"Unknown field":            {input: "title,budget", err: ErrUnknownField},
+		"Unknown nested field":     {input: "imdb.id", err: ErrUnknownField},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			selection, err := w.Parse(tt.input)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.projection, selection.Projection())
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	w := New(item{})
+	items := []item{
+		{ID: "1", Title: "One", Tags: []string{"a"}, Rating: rating{Score: 7.5, Votes: 12345678901}},
+		{ID: "2", Title: "Two", Optional: &rating{Score: 1}},
+	}
+
+	selection, err := w.Parse("title,rating.votes,optional.score")
+	require.NoError(t, err)
+
+	result, err := selection.Apply(items)
+	require.NoError(t, err)
+	assert.Equal(t, []any{
+		map[string]any{"id": "1", "title": "One", "rating": map[string]any{"votes": json.Number("12345678901")}},
+		map[string]any{"id": "2", "title": "Two", "rating": map[string]any{"votes": json.Number("0")}, "optional": map[string]any{"score": json.Number("1")}},
+	}, result)
+
+	var all *Selection
+	unchanged, err := all.Apply(items)
+	require.NoError(t, err)
+	assert.Equal(t, items, unchanged)
+}
@@ -0,0 +1,204 @@
+// Package health reports whether the service and the dependencies it needs
+// to serve requests are working.
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultTimeout bounds each check when no timeout is given.
+const DefaultTimeout = 2 * time.Second
+
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusFail Status = "fail"
+)
+
+// Checker is a single readiness check. Check should return promptly once
+// ctx is done.
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+// NewChecker creates a Checker from a function.
+func NewChecker(name string, check func(ctx context.Context) error) Checker {
+	return &funcChecker{name: name, check: check}
+}
+
+type funcChecker struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+func (c *funcChecker) Name() string {
+	return c.name
+}
+
+func (c *funcChecker) Check(ctx context.Context) error {
+	return c.check(ctx)
+}
+
+// CheckResult is the outcome of one check.
+type CheckResult struct {
+	Name       string `json:"name"`
+	Status     Status `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// Report is the outcome of every registered check. Status is only ok if
+// every check passed.
+type Report struct {
+	Status Status        `json:"status"`
+	Checks []CheckResult `json:"checks"`
+}
+
+// Registry holds the checks that decide whether the service is ready.
+// Checks can be registered at any time by the subsystems they cover.
+type Registry struct {
+	timeout time.Duration
+
+	mu       sync.RWMutex
+	checkers []Checker
+}
+
+// NewRegistry creates an empty Registry that gives each check up to
+// timeout to complete.
+func NewRegistry(timeout time.Duration) *Registry {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Registry{timeout: timeout}
+}
+
+// Register adds checkers to the registry.
+func (r *Registry) Register(checkers ...Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkers = append(r.checkers, checkers...)
+}
+
+// Check runs every check concurrently and reports the results in the order
+// the checks were registered.
+func (r *Registry) Check(ctx context.Context) Report {
+	r.mu.RLock()
+	checkers := append([]Checker(nil), r.checkers...)
+	r.mu.RUnlock()
+
+	report := Report{
+		Status: StatusOK,
+		Checks: make([]CheckResult, len(checkers)),
+	}
+
+	var wg sync.WaitGroup
+	for i, checker := range checkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Checks[i] = r.run(ctx, checker)
+		}()
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+
+	return report
+}
+
+func (r *Registry) run(ctx context.Context, checker Checker) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	// The check runs separately so a check ignoring its context cannot hold
+	// up the report.
+	done := make(chan error, 1)
+	go func() {
+		done <- checker.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := CheckResult{
+		Name:       checker.Name(),
+		Status:     StatusOK,
+		DurationMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+var ErrDraining = errors.New("server is shutting down")
+
+// Drain is a check that fails once the server starts shutting down, so load
+// balancers stop sending it new requests.
+type Drain struct {
+	draining atomic.Bool
+}
+
+// Start marks the server as draining.
+func (d *Drain) Start() {
+	d.draining.Store(true)
+}
+
+func (d *Drain) Name() string {
+	return "draining"
+}
+
+func (d *Drain) Check(context.Context) error {
+	if d.draining.Load() {
+		return ErrDraining
+	}
+	return nil
+}
+
+// ErrStarting is returned by a Startup check whose task has not finished.
+var ErrStarting = errors.New("still starting")
+
+// Startup is a check that fails until a task run at startup, such as a
+// backfill that some endpoints depend on, has finished.
+type Startup struct {
+	name string
+	done atomic.Bool
+}
+
+// NewStartup creates a Startup check with the given name.
+func NewStartup(name string) *Startup {
+	return &Startup{name: name}
+}
+
+// Done marks the task as finished.
+func (s *Startup) Done() {
+	s.done.Store(true)
+}
+
+func (s *Startup) Name() string {
+	return s.name
+}
+
+func (s *Startup) Check(context.Context) error {
+	if !s.done.Load() {
+		return ErrStarting
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_Check(t *testing.T) {
+	ok := NewChecker("ok", func(context.Context) error { return nil })
+	failing := NewChecker("failing", func(context.Context) error { return errors.New("unreachable") })
+	// stuck ignores its context, the registry must still give up on it.
+	stuck := NewChecker("stuck", func(context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	tests := map[string]struct {
+		checkers []Checker
+		expected Report
+	}{
+		"No checks": {
+			expected: Report{Status: StatusOK, Checks: []CheckResult{}},
+		},
+		"All checks pass": {
+			checkers: []Checker{ok},
+			expected: Report{Status: StatusOK, Checks: []CheckResult{
+				{Name: "ok", Status: StatusOK},
+			}},
+		},
+		"A check fails": {
+			checkers: []Checker{ok, failing},
+			expected: Report{Status: StatusFail, Checks: []CheckResult{
+				{Name: "ok", Status: StatusOK},
+				{Name: "failing", Status: StatusFail, Error: "unreachable"},
+			}},
+		},
+		"A check times out": {
+			checkers: []Checker{stuck, ok},
+			expected: Report{Status: StatusFail, Checks: []CheckResult{
+				{Name: "stuck", Status: StatusFail, Error: context.DeadlineExceeded.Error()},
+				{Name: "ok", Status: StatusOK},
+			}},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := NewRegistry(20 * time.Millisecond)
+			r.Register(tt.checkers...)
+
+			got := r.Check(context.Background())
+			for i := range got.Checks {
+				got.Checks[i].DurationMS = 0
+			}
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestDrain(t *testing.T) {
+	var d Drain
+	assert.NoError(t, d.Check(context.Background()))
+
+	d.Start()
+	assert.Equal(t, ErrDraining, d.Check(context.Background()))
+}
+
+func TestStartup(t *testing.T) {
+	s := NewStartup("backfill")
+	assert.Equal(t, "backfill", s.Name())
+	assert.Equal(t, ErrStarting, s.Check(context.Background()))
+
+	s.Done()
+	assert.NoError(t, s.Check(context.Background()))
+}
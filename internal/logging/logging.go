@@ -0,0 +1,99 @@
+// Package logging builds the application's structured logger and carries
+// request-scoped loggers through contexts.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/yasv98/movies-api/internal/config"
+)
+
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// New returns a logger writing to w in the format set in cfg. Its level is
+// read from level, which is set to the one in cfg so it can be changed
+// later. It logs JSON at info level by default.
+func New(w io.Writer, cfg config.Logging, level *slog.LevelVar) (*slog.Logger, error) {
+	l, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+	level.Set(l)
+
+	opts := &slog.HandlerOptions{Level: level}
+	switch cfg.Format {
+	case "", FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	case FormatText:
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q", cfg.Format)
+	}
+}
+
+// ParseLevel converts a level name into a slog.Level, treating an empty name
+// as info.
+func ParseLevel(name string) (slog.Level, error) {
+	if name == "" {
+		return slog.LevelInfo, nil
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.ToUpper(name))); err != nil {
+		return 0, fmt.Errorf("unknown log level %q", name)
+	}
+
+	return level, nil
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying logger.
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger carried by ctx, falling back to the default
+// logger when there is none.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// WithLatency returns a logger that adds the time elapsed since start to
+// every line it writes.
+func WithLatency(logger *slog.Logger, start time.Time) *slog.Logger {
+	return slog.New(&latencyHandler{Handler: logger.Handler(), start: start})
+}
+
+// LatencyKey is the attribute holding the milliseconds elapsed since a
+// request started.
+const LatencyKey = "latency_ms"
+
+type latencyHandler struct {
+	slog.Handler
+	start time.Time
+}
+
+func (h *latencyHandler) Handle(ctx context.Context, record slog.Record) error {
+	record.AddAttrs(slog.Float64(LatencyKey, float64(time.Since(h.start).Microseconds())/1000))
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *latencyHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &latencyHandler{Handler: h.Handler.WithAttrs(attrs), start: h.start}
+}
+
+func (h *latencyHandler) WithGroup(name string) slog.Handler {
+	return &latencyHandler{Handler: h.Handler.WithGroup(name), start: h.start}
+}
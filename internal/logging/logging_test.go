@@ -0,0 +1,83 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yasv98/movies-api/internal/config"
+)
+
+func TestNew(t *testing.T) {
+	tests := map[string]struct {
+		cfg         config.Logging
+		assertError assert.ErrorAssertionFunc
+		debug       bool
+		json        bool
+	}{
+		"Defaults": {
+			cfg:         config.Logging{},
+			assertError: assert.NoError,
+			json:        true,
+		},
+		"Debug text": {
+			cfg:         config.Logging{Level: "debug", Format: FormatText},
+			assertError: assert.NoError,
+			debug:       true,
+		},
+		"Upper case level": {
+			cfg:         config.Logging{Level: "WARN"},
+			assertError: assert.NoError,
+			json:        true,
+		},
+		"Unknown level": {
+			cfg:         config.Logging{Level: "verbose"},
+			assertError: assert.Error,
+		},
+		"Unknown format": {
+			cfg:         config.Logging{Format: "xml"},
+			assertError: assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger, err := New(&buf, tt.cfg, new(slog.LevelVar))
+			tt.assertError(t, err)
+			if err != nil {
+				return
+			}
+
+			assert.Equal(t, tt.debug, logger.Enabled(context.Background(), slog.LevelDebug))
+
+			logger.Warn("hello")
+			assert.Equal(t, tt.json, json.Valid(buf.Bytes()))
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	assert.Same(t, slog.Default(), FromContext(context.Background()))
+
+	logger := slog.New(slog.NewJSONHandler(&bytes.Buffer{}, nil))
+	assert.Same(t, logger, FromContext(NewContext(context.Background(), logger)))
+}
+
+func TestWithLatency(t *testing.T) {
+	var buf bytes.Buffer
+	base := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	logger := WithLatency(base, time.Now().Add(-time.Second)).With("request_id", "abc")
+	logger.Info("done")
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "abc", line["request_id"])
+	assert.GreaterOrEqual(t, line[LatencyKey], float64(1000))
+}
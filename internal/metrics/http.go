@@ -0,0 +1,22 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+)
+
+// UnmatchedRoute labels requests that did not match a route, so arbitrary
+// paths cannot create unbounded label values.
+const UnmatchedRoute = "unmatched"
+
+// ObserveRequest records a handled HTTP request. route is the route
+// template, such as /api/v1/movies/:movieId, rather than the request path.
+func (m *Metrics) ObserveRequest(route, method string, status int, duration time.Duration) {
+	if route == "" {
+		route = UnmatchedRoute
+	}
+	labels := []string{route, method, strconv.Itoa(status)}
+
+	m.requests.WithLabelValues(labels...).Inc()
+	m.requestDuration.WithLabelValues(labels...).Observe(duration.Seconds())
+}
@@ -0,0 +1,104 @@
+// Package metrics collects Prometheus metrics for HTTP requests and MongoDB
+// commands and connections.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics holds the collectors recorded by the HTTP middleware and the
+// MongoDB monitors.
+type Metrics struct {
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+
+	commandDuration *prometheus.HistogramVec
+	commandFailures *prometheus.CounterVec
+
+	connectionsOpen   prometheus.Gauge
+	connectionsInUse  prometheus.Gauge
+	checkoutFailures  *prometheus.CounterVec
+	poolClears        prometheus.Counter
+	checkoutDurations prometheus.Histogram
+
+	gatherer prometheus.Gatherer
+}
+
+// New creates the collectors on a registry of their own, along with the
+// standard Go runtime and process collectors.
+func New() *Metrics {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Number of HTTP requests handled, by route, method and status.",
+		}, []string{"route", "method", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Time taken to handle HTTP requests, by route, method and status.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+
+		commandDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "mongodb_command_duration_seconds",
+			Help:    "Time taken by MongoDB commands, by collection and command.",
+			Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"collection", "command"}),
+		commandFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "mongodb_command_failures_total",
+			Help: "Number of failed MongoDB commands, by collection and command.",
+		}, []string{"collection", "command"}),
+
+		connectionsOpen: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "mongodb_pool_connections_open",
+			Help: "Number of open connections in the MongoDB connection pool.",
+		}),
+		connectionsInUse: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "mongodb_pool_connections_in_use",
+			Help: "Number of MongoDB connections checked out of the pool.",
+		}),
+		checkoutFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "mongodb_pool_checkout_failures_total",
+			Help: "Number of failed attempts to check a connection out of the pool, by reason.",
+		}, []string{"reason"}),
+		poolClears: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "mongodb_pool_clears_total",
+			Help: "Number of times the MongoDB connection pool was cleared.",
+		}),
+		checkoutDurations: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "mongodb_pool_checkout_duration_seconds",
+			Help:    "Time taken to check a connection out of the pool.",
+			Buckets: []float64{.0001, .0005, .001, .005, .01, .05, .1, .5, 1},
+		}),
+
+		gatherer: registry,
+	}
+
+	registry.MustRegister(
+		m.requests,
+		m.requestDuration,
+		m.commandDuration,
+		m.commandFailures,
+		m.connectionsOpen,
+		m.connectionsInUse,
+		m.checkoutFailures,
+		m.poolClears,
+		m.checkoutDurations,
+	)
+
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.gatherer, promhttp.HandlerOpts{})
+}
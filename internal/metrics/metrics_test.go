@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+func TestObserveRequest(t *testing.T) {
+	m := New()
+	m.ObserveRequest("/api/v1/movies/:movieId", http.MethodGet, http.StatusOK, 10*time.Millisecond)
+	m.ObserveRequest("/api/v1/movies/:movieId", http.MethodGet, http.StatusOK, 20*time.Millisecond)
+	m.ObserveRequest("", http.MethodGet, http.StatusNotFound, time.Millisecond)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.requests.WithLabelValues("/api/v1/movies/:movieId", "GET", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues(UnmatchedRoute, "GET", "404")))
+}
+
+func TestCommandMonitor(t *testing.T) {
+	m := New()
+	monitor := m.CommandMonitor()
+	ctx := context.Background()
+
+	find, err := bson.Marshal(bson.D{{Key: "find", Value: "movies"}})
+	require.NoError(t, err)
+
+	monitor.Started(ctx, &event.CommandStartedEvent{CommandName: "find", Command: find, RequestID: 1})
+	monitor.Started(ctx, &event.CommandStartedEvent{CommandName: "find", Command: find, RequestID: 2})
+	monitor.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: event.CommandFinishedEvent{
+		CommandName: "find", RequestID: 1, Duration: time.Millisecond,
+	}})
+	monitor.Failed(ctx, &event.CommandFailedEvent{CommandFinishedEvent: event.CommandFinishedEvent{
+		CommandName: "find", RequestID: 2, Duration: time.Millisecond,
+	}, Failure: "timeout"})
+
+	assert.Equal(t, 1, testutil.CollectAndCount(m.commandDuration))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.commandFailures.WithLabelValues("movies", "find")))
+}
+
+func TestPoolMonitor(t *testing.T) {
+	m := New()
+	monitor := m.PoolMonitor()
+
+	for _, eventType := range []string{
+		event.ConnectionCreated,
+		event.ConnectionCreated,
+		event.GetSucceeded,
+		event.GetSucceeded,
+		event.ConnectionReturned,
+		event.ConnectionClosed,
+		event.PoolCleared,
+	} {
+		monitor.Event(&event.PoolEvent{Type: eventType})
+	}
+	monitor.Event(&event.PoolEvent{Type: event.GetFailed, Reason: event.ReasonTimedOut})
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.connectionsOpen))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.connectionsInUse))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.poolClears))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.checkoutFailures.WithLabelValues(event.ReasonTimedOut)))
+}
+
+func TestHandler(t *testing.T) {
+	m := New()
+	m.ObserveRequest("/healthz", http.MethodGet, http.StatusOK, time.Millisecond)
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `http_requests_total{method="GET",route="/healthz",status="200"} 1`)
+	assert.Contains(t, w.Body.String(), "go_goroutines")
+}
@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+
+	"github.com/yasv98/movies-api/internal/mongocommand"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// unknownCollection labels commands that do not target a collection, such
+// as ping.
+const unknownCollection = "none"
+
+// CommandMonitor records the duration and failures of every command sent to
+// MongoDB.
+func (m *Metrics) CommandMonitor() *event.CommandMonitor {
+	// The finished events do not include the command, so the collection is
+	// remembered from the started event until then.
+	var collections sync.Map
+
+	finished := func(e event.CommandFinishedEvent) (collection string) {
+		if value, ok := collections.LoadAndDelete(e.RequestID); ok {
+			return value.(string)
+		}
+		return unknownCollection
+	}
+
+	return &event.CommandMonitor{
+		Started: func(_ context.Context, e *event.CommandStartedEvent) {
+			collections.Store(e.RequestID, commandCollection(e.CommandName, e.Command))
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			collection := finished(e.CommandFinishedEvent)
+			m.commandDuration.WithLabelValues(collection, e.CommandName).Observe(e.Duration.Seconds())
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			collection := finished(e.CommandFinishedEvent)
+			m.commandDuration.WithLabelValues(collection, e.CommandName).Observe(e.Duration.Seconds())
+			m.commandFailures.WithLabelValues(collection, e.CommandName).Inc()
+		},
+	}
+}
+
+// commandCollection labels a command with the collection it targets.
+func commandCollection(commandName string, command bson.Raw) string {
+	if collection, ok := mongocommand.Collection(commandName, command); ok {
+		return collection
+	}
+	return unknownCollection
+}
+
+// PoolMonitor records the state of the connection pool.
+func (m *Metrics) PoolMonitor() *event.PoolMonitor {
+	return &event.PoolMonitor{
+		Event: func(e *event.PoolEvent) {
+			switch e.Type {
+			case event.ConnectionCreated:
+				m.connectionsOpen.Inc()
+			case event.ConnectionClosed:
+				m.connectionsOpen.Dec()
+			case event.GetSucceeded:
+				m.connectionsInUse.Inc()
+				m.checkoutDurations.Observe(e.Duration.Seconds())
+			case event.ConnectionReturned:
+				m.connectionsInUse.Dec()
+			case event.GetFailed:
+				m.checkoutFailures.WithLabelValues(e.Reason).Inc()
+			case event.PoolCleared:
+				m.poolClears.Inc()
+			}
+		},
+	}
+}
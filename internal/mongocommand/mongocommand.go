@@ -0,0 +1,19 @@
+// Package mongocommand inspects commands sent to MongoDB, as seen by the
+// driver's command monitors.
+package mongocommand
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Collection returns the collection a command targets. Most commands name
+// it as the value of their first element, e.g. {find: "movies", ...}. It
+// returns false for commands that do not target a collection, such as
+// ping.
+func Collection(commandName string, command bson.Raw) (string, bool) {
+	value, err := command.LookupErr(commandName)
+	if err != nil {
+		return "", false
+	}
+	return value.StringValueOK()
+}
@@ -0,0 +1,34 @@
+package mongocommand
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestCollection(t *testing.T) {
+	tests := map[string]struct {
+		name       string
+		command    bson.D
+		expected   string
+		expectedOK bool
+	}{
+		"Find":              {name: "find", command: bson.D{{Key: "find", Value: "movies"}, {Key: "filter", Value: bson.D{}}}, expected: "movies", expectedOK: true},
+		"Aggregate":         {name: "aggregate", command: bson.D{{Key: "aggregate", Value: "comments"}}, expected: "comments", expectedOK: true},
+		"No collection":     {name: "ping", command: bson.D{{Key: "ping", Value: 1}}},
+		"Command not found": {name: "find", command: bson.D{{Key: "insert", Value: "movies"}}},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			raw, err := bson.Marshal(tt.command)
+			require.NoError(t, err)
+
+			collection, ok := Collection(tt.name, raw)
+			assert.Equal(t, tt.expected, collection)
+			assert.Equal(t, tt.expectedOK, ok)
+		})
+	}
+}
@@ -0,0 +1,180 @@
+// Package ratelimit limits how often clients may call the API using token
+// buckets, each client having one bucket for reads and one for writes.
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/yasv98/movies-api/internal/config"
+)
+
+// Rule allows Requests per Period on average, with bursts of up to Burst
+// requests.
+type Rule struct {
+	Requests int
+	Period   time.Duration
+	// Burst is the bucket's capacity, Requests when unset.
+	Burst int
+}
+
+// Unlimited reports whether the rule allows every request.
+func (r Rule) Unlimited() bool {
+	return r.Requests <= 0 || r.Period <= 0
+}
+
+// Capacity returns the most requests that can be made at once.
+func (r Rule) Capacity() int {
+	if r.Burst > 0 {
+		return r.Burst
+	}
+	return r.Requests
+}
+
+// rate returns the tokens added to the bucket per second.
+func (r Rule) rate() float64 {
+	return float64(r.Requests) / r.Period.Seconds()
+}
+
+// Policy holds the rules for read and write requests.
+type Policy struct {
+	Read  Rule
+	Write Rule
+	// APIKeys holds the hashes of the API keys clients may be identified
+	// by, see HashAPIKey.
+	APIKeys map[string]bool
+}
+
+// HashAPIKey returns the hash API keys are known and counted by, so the keys
+// themselves are not kept in memory or in the store.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:16])
+}
+
+// NewPolicy converts the configured limits into a Policy. Every request is
+// allowed when rate limiting is disabled.
+func NewPolicy(cfg config.RateLimit) Policy {
+	if !cfg.Enabled {
+		return Policy{}
+	}
+
+	apiKeys := make(map[string]bool, len(cfg.APIKeys))
+	for _, key := range cfg.APIKeys {
+		apiKeys[HashAPIKey(key)] = true
+	}
+
+	return Policy{
+		Read:    Rule{Requests: cfg.Read.Requests, Period: cfg.Read.Period, Burst: cfg.Read.Burst},
+		Write:   Rule{Requests: cfg.Write.Requests, Period: cfg.Write.Period, Burst: cfg.Write.Burst},
+		APIKeys: apiKeys,
+	}
+}
+
+// Result describes a client's bucket after a request was counted.
+type Result struct {
+	Allowed bool
+	// Remaining is the number of requests that can be made right away.
+	Remaining int
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is how long until the next request is allowed, zero when
+	// Allowed.
+	RetryAfter time.Duration
+}
+
+// Store keeps the buckets. The in-memory store suits a single replica,
+// replicas sharing limits need a Store backed by a shared database.
+type Store interface {
+	// Take removes a token from the bucket for key, refilled according to
+	// rule as of now, reporting whether there was one to take.
+	Take(ctx context.Context, key string, rule Rule, now time.Time) (Result, error)
+}
+
+// Limiter applies a Policy, which can be replaced while it is in use.
+type Limiter struct {
+	store  Store
+	policy atomic.Pointer[Policy]
+	now    func() time.Time
+}
+
+func NewLimiter(store Store, policy Policy) *Limiter {
+	l := &Limiter{store: store, now: time.Now}
+	l.SetPolicy(policy)
+	return l
+}
+
+// SetPolicy replaces the rules applied to subsequent requests.
+func (l *Limiter) SetPolicy(policy Policy) {
+	l.policy.Store(&policy)
+}
+
+// KnownAPIKey reports whether key is one of the policy's API keys. Unknown
+// keys must not be used to identify clients, or a client could get a fresh
+// allowance by sending a new key with each request.
+func (l *Limiter) KnownAPIKey(key string) bool {
+	return l.policy.Load().APIKeys[HashAPIKey(key)]
+}
+
+// Allow counts a request from the client identified by key against the read
+// or write rule, returning the rule applied. Requests are always allowed
+// when the rule is unlimited.
+func (l *Limiter) Allow(ctx context.Context, key string, write bool) (Result, Rule, error) {
+	policy := l.policy.Load()
+
+	rule, class := policy.Read, "read"
+	if write {
+		rule, class = policy.Write, "write"
+	}
+	if rule.Unlimited() {
+		return Result{Allowed: true}, rule, nil
+	}
+
+	result, err := l.store.Take(ctx, class+":"+key, rule, l.now())
+	return result, rule, err
+}
+
+// bucket is a token bucket as of updated.
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// take refills b up to now and removes a token if there is one.
+func (b *bucket) take(rule Rule, now time.Time) Result {
+	capacity := float64(rule.Capacity())
+	rate := rule.rate()
+
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(capacity, b.tokens+elapsed*rate)
+		b.updated = now
+	}
+	// The capacity may have shrunk since the bucket was filled.
+	b.tokens = math.Min(capacity, b.tokens)
+
+	result := Result{Allowed: b.tokens >= 1}
+	if result.Allowed {
+		b.tokens--
+	} else {
+		result.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = seconds((capacity - b.tokens) / rate)
+
+	return result
+}
+
+// full reports whether b would have refilled completely by now, in which
+// case it is no different to a new bucket.
+func (b *bucket) full(rule Rule, now time.Time) bool {
+	missing := float64(rule.Capacity()) - b.tokens
+	return now.Sub(b.updated).Seconds()*rule.rate() >= missing
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
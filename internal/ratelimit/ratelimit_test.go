@@ -0,0 +1,127 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yasv98/movies-api/internal/config"
+)
+
+func TestMemoryStore_Take(t *testing.T) {
+	store := NewMemoryStore()
+	rule := Rule{Requests: 60, Period: time.Minute, Burst: 2}
+	now := time.Now()
+	ctx := context.Background()
+
+	result, err := store.Take(ctx, "client", rule, now)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Allowed: true, Remaining: 1, Reset: time.Second}, result)
+
+	result, err = store.Take(ctx, "client", rule, now)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Allowed: true, Remaining: 0, Reset: 2 * time.Second}, result)
+
+	result, err = store.Take(ctx, "client", rule, now)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Allowed: false, Remaining: 0, Reset: 2 * time.Second, RetryAfter: time.Second}, result)
+
+	// Other clients have buckets of their own.
+	result, err = store.Take(ctx, "other", rule, now)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	// A token is added every second.
+	result, err = store.Take(ctx, "client", rule, now.Add(time.Second))
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+}
+
+func TestMemoryStore_ShrunkCapacity(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Now()
+	ctx := context.Background()
+
+	_, err := store.Take(ctx, "client", Rule{Requests: 10, Period: time.Minute}, now)
+	require.NoError(t, err)
+
+	result, err := store.Take(ctx, "client", Rule{Requests: 2, Period: time.Minute}, now)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 1, result.Remaining)
+}
+
+func TestMemoryStore_Sweep(t *testing.T) {
+	store := NewMemoryStore()
+	rule := Rule{Requests: 60, Period: time.Minute}
+	now := time.Now()
+	ctx := context.Background()
+
+	_, err := store.Take(ctx, "idle", rule, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, store.Len())
+
+	// By the next sweep the idle bucket has refilled and is dropped.
+	_, err = store.Take(ctx, "active", rule, now.Add(sweepInterval))
+	require.NoError(t, err)
+	assert.Equal(t, 1, store.Len())
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	limiter := NewLimiter(NewMemoryStore(), Policy{
+		Read:  Rule{Requests: 2, Period: time.Minute},
+		Write: Rule{Requests: 1, Period: time.Minute},
+	})
+	ctx := context.Background()
+
+	result, rule, err := limiter.Allow(ctx, "client", true)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 1, rule.Requests)
+
+	result, _, err = limiter.Allow(ctx, "client", true)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+
+	// Reads are counted separately from writes.
+	result, rule, err = limiter.Allow(ctx, "client", false)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 2, rule.Requests)
+
+	limiter.SetPolicy(Policy{})
+	result, rule, err = limiter.Allow(ctx, "client", true)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.True(t, rule.Unlimited())
+}
+
+func TestNewPolicy(t *testing.T) {
+	cfg := config.RateLimit{
+		Read:    config.RateLimitRule{Requests: 100, Period: time.Minute, Burst: 20},
+		Write:   config.RateLimitRule{Requests: 10, Period: time.Minute},
+		APIKeys: []string{"secret"},
+	}
+
+	assert.Equal(t, Policy{}, NewPolicy(cfg))
+
+	cfg.Enabled = true
+	assert.Equal(t, Policy{
+		Read:    Rule{Requests: 100, Period: time.Minute, Burst: 20},
+		Write:   Rule{Requests: 10, Period: time.Minute},
+		APIKeys: map[string]bool{HashAPIKey("secret"): true},
+	}, NewPolicy(cfg))
+}
+
+func TestLimiter_KnownAPIKey(t *testing.T) {
+	limiter := NewLimiter(NewMemoryStore(), NewPolicy(config.RateLimit{Enabled: true, APIKeys: []string{"secret"}}))
+
+	assert.True(t, limiter.KnownAPIKey("secret"))
+	assert.False(t, limiter.KnownAPIKey("guess"))
+
+	limiter.SetPolicy(Policy{})
+	assert.False(t, limiter.KnownAPIKey("secret"))
+}
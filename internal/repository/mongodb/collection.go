@@ -0,0 +1,114 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yasv98/movies-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type collectionRepository struct {
+	db *mongo.Database
+}
+
+func NewCollectionRepository(db *mongo.Database) domain.CollectionRepository {
+	return &collectionRepository{db: db}
+}
+
+func (r *collectionRepository) Create(ctx context.Context, collection *domain.Collection) error {
+	now := primitive.NewDateTimeFromTime(time.Now())
+	collection.ID = primitive.NewObjectID()
+	collection.CreatedAt = now
+	collection.UpdatedAt = now
+
+	if _, err := r.db.Collection("collections").InsertOne(ctx, collection); err != nil {
+		return fmt.Errorf("failed to create collection: %w", err)
+	}
+
+	return nil
+}
+
+func (r *collectionRepository) Get(ctx context.Context, id primitive.ObjectID) (*domain.Collection, error) {
+	var collection domain.Collection
+	if err := r.db.Collection("collections").FindOne(ctx, bson.M{"_id": id}).Decode(&collection); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrCollectionNotFound
+		}
+		return nil, err
+	}
+
+	return &collection, nil
+}
+
+func (r *collectionRepository) Update(ctx context.Context, collection *domain.Collection) error {
+	collection.UpdatedAt = primitive.NewDateTimeFromTime(time.Now())
+
+	result, err := r.db.Collection("collections").UpdateOne(
+		ctx,
+		bson.M{"_id": collection.ID},
+		bson.M{"$set": bson.M{
+			"title":       collection.Title,
+			"description": collection.Description,
+			"movie_ids":   collection.MovieIDs,
+			"visibility":  collection.Visibility,
+			"updated_at":  collection.UpdatedAt,
+		}},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update collection: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrCollectionNotFound
+	}
+
+	return nil
+}
+
+func (r *collectionRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.db.Collection("collections").DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return fmt.Errorf("failed to delete collection: %w", err)
+	}
+
+	if result.DeletedCount == 0 {
+		return domain.ErrCollectionNotFound
+	}
+
+	return nil
+}
+
+func (r *collectionRepository) ListPublic(ctx context.Context, page, limit int) ([]domain.Collection, error) {
+	return r.list(ctx, bson.M{"visibility": domain.CollectionPublic}, page, limit)
+}
+
+func (r *collectionRepository) ListByOwner(ctx context.Context, owner string, page, limit int) ([]domain.Collection, error) {
+	return r.list(ctx, bson.M{"owner": owner}, page, limit)
+}
+
+func (r *collectionRepository) list(ctx context.Context, filter bson.M, page, limit int) ([]domain.Collection, error) {
+	skip := (page - 1) * limit
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "updated_at", Value: -1}}).
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.db.Collection("collections").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find collections: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var collections []domain.Collection
+	if err = cursor.All(ctx, &collections); err != nil {
+		return nil, err
+	}
+
+	return collections, nil
+}
@@ -9,6 +9,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type commentRepository struct {
@@ -23,50 +24,89 @@ func (r *commentRepository) Create(ctx context.Context, comment *domain.Comment)
 	comment.ID = primitive.NewObjectID()
 	comment.Date = primitive.NewDateTimeFromTime(time.Now())
 
-	_, err := r.db.Collection("comments").InsertOne(ctx, comment)
-	return err
+	if _, err := r.db.Collection("comments").InsertOne(ctx, comment); err != nil {
+		return err
+	}
+
+	// Keep the parent's reply count in step with its replies.
+	if comment.ParentID != nil {
+		if err := r.incrementReplyCount(ctx, *comment.ParentID, 1); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func (r *commentRepository) Update(ctx context.Context, comment *domain.Comment) error {
-	update := bson.M{
-		"$set": bson.M{
-			"name":  comment.Name,
-			"email": comment.Email,
-			"text":  comment.Text,
-			"date":  time.Now(),
-		},
+	set := bson.M{
+		"name":  comment.Name,
+		"email": comment.Email,
+		"text":  comment.Text,
+		"date":  time.Now(),
 	}
+	// Only a content filter holding the edit changes the status here, other
+	// transitions belong to moderation.
+	if comment.Status != domain.CommentStatusVisible {
+		set["status"] = comment.Status
+	}
+	update := bson.M{"$set": set}
 
-	result, err := r.db.Collection("comments").UpdateOne(
-		ctx,
-		bson.M{
-			"_id":      comment.ID,
-			"movie_id": comment.MovieID,
-		},
-		update,
-	)
+	filter := bson.M{
+		"_id":      comment.ID,
+		"movie_id": comment.MovieID,
+	}
+	// Comments taken down by moderation stay down, otherwise editing one
+	// would put it back in view or back in the queue.
+	editable := bson.M{
+		"_id":      comment.ID,
+		"movie_id": comment.MovieID,
+		"status": bson.M{"$nin": bson.A{
+			domain.CommentStatusHidden,
+			domain.CommentStatusRejected,
+		}},
+	}
+
+	result, err := r.db.Collection("comments").UpdateOne(ctx, editable, update)
 	if err != nil {
 		return fmt.Errorf("failed to update comment: %w", err)
 	}
 
 	if result.MatchedCount == 0 {
-		return domain.ErrCommentNotFoundForMovie
+		count, err := r.db.Collection("comments").CountDocuments(ctx, filter, options.Count().SetLimit(1))
+		if err != nil {
+			return fmt.Errorf("failed to find comment: %w", err)
+		}
+		if count == 0 {
+			return domain.ErrCommentNotFoundForMovie
+		}
+		return domain.ErrCommentNotEditable
 	}
 
 	return nil
 }
 
 func (r *commentRepository) Delete(ctx context.Context, movieID, commentID primitive.ObjectID) error {
-	result, err := r.db.Collection("comments").DeleteOne(ctx, bson.M{
+	var deleted domain.Comment
+	err := r.db.Collection("comments").FindOneAndDelete(ctx, bson.M{
 		"_id":      commentID,
 		"movie_id": movieID,
-	})
+	}).Decode(&deleted)
+	if err == mongo.ErrNoDocuments {
+		return domain.ErrCommentNotFoundForMovie
+	}
 	if err != nil {
 		return fmt.Errorf("failed to delete comment: %w", err)
 	}
 
-	if result.DeletedCount == 0 {
-		return domain.ErrCommentNotFoundForMovie
+	if deleted.ParentID != nil {
+		if err := r.incrementReplyCount(ctx, *deleted.ParentID, -1); err != nil {
+			return err
+		}
+	}
+
+	if _, err := r.db.Collection("comment_reactions").DeleteMany(ctx, bson.M{"comment_id": commentID}); err != nil {
+		return fmt.Errorf("failed to delete comment reactions: %w", err)
 	}
 
 	// Decrement the movie's comment count as it has been deleted.
@@ -89,18 +129,26 @@ func (r *commentRepository) Delete(ctx context.Context, movieID, commentID primi
 
 func (r *commentRepository) GetMovieComment(ctx context.Context, movieID, commentID primitive.ObjectID) (*domain.Comment, error) {
 	var comment domain.Comment
-	if err := r.db.Collection("comments").FindOne(ctx, bson.M{
+	if err := r.db.Collection("comments").FindOne(ctx, visible(bson.M{
 		"_id":      commentID,
 		"movie_id": movieID,
-	}).Decode(&comment); err != nil {
+	})).Decode(&comment); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrCommentNotFoundForMovie
+		}
 		return nil, err
 	}
 
 	return &comment, nil
 }
 
-func (r *commentRepository) GetMovieComments(ctx context.Context, movieID primitive.ObjectID) ([]domain.Comment, error) {
-	cursor, err := r.db.Collection("comments").Find(ctx, bson.M{"movie_id": movieID})
+func (r *commentRepository) GetMovieComments(ctx context.Context, movieID primitive.ObjectID, sort domain.CommentSort) ([]domain.Comment, error) {
+	opts := options.Find()
+	if sort == domain.CommentSortTop {
+		opts.SetSort(bson.D{{Key: "reaction_count", Value: -1}, {Key: "date", Value: -1}})
+	}
+
+	cursor, err := r.db.Collection("comments").Find(ctx, visible(bson.M{"movie_id": movieID}), opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find comments: %w", err)
 	}
@@ -113,3 +161,59 @@ func (r *commentRepository) GetMovieComments(ctx context.Context, movieID primit
 
 	return comments, nil
 }
+
+func (r *commentRepository) GetCommentReplies(ctx context.Context, movieID, parentID primitive.ObjectID) ([]domain.Comment, error) {
+	cursor, err := r.db.Collection("comments").Find(ctx, visible(bson.M{
+		"movie_id":  movieID,
+		"parent_id": parentID,
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find replies: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var replies []domain.Comment
+	if err = cursor.All(ctx, &replies); err != nil {
+		return nil, err
+	}
+
+	return replies, nil
+}
+
+func (r *commentRepository) CountDuplicates(ctx context.Context, comment *domain.Comment, since time.Time) (int64, error) {
+	count, err := r.db.Collection("comments").CountDocuments(ctx, bson.M{
+		"_id":   bson.M{"$ne": comment.ID},
+		"email": comment.Email,
+		"text":  comment.Text,
+		"date":  bson.M{"$gte": since},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count duplicate comments: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *commentRepository) incrementReplyCount(ctx context.Context, commentID primitive.ObjectID, delta int) error {
+	_, err := r.db.Collection("comments").UpdateOne(
+		ctx,
+		bson.M{"_id": commentID},
+		bson.M{"$inc": bson.M{"reply_count": delta}},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update reply count: %w", err)
+	}
+
+	return nil
+}
+
+// visible restricts filter to comments that have not been hidden by
+// moderation.
+func visible(filter bson.M) bson.M {
+	filter["status"] = bson.M{"$nin": bson.A{
+		domain.CommentStatusPending,
+		domain.CommentStatusHidden,
+		domain.CommentStatusRejected,
+	}}
+	return filter
+}
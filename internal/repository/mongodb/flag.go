@@ -0,0 +1,33 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yasv98/movies-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type flagRepository struct {
+	db *mongo.Database
+}
+
+func NewFlagRepository(db *mongo.Database) domain.FlagRepository {
+	return &flagRepository{db: db}
+}
+
+func (r *flagRepository) Create(ctx context.Context, flag *domain.Flag) error {
+	flag.ID = primitive.NewObjectID()
+	flag.Date = primitive.NewDateTimeFromTime(time.Now())
+
+	if _, err := r.db.Collection("comment_flags").InsertOne(ctx, flag); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrAlreadyFlagged
+		}
+		return fmt.Errorf("failed to create flag: %w", err)
+	}
+
+	return nil
+}
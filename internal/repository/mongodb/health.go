@@ -0,0 +1,82 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/yasv98/movies-api/internal/health"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// PingChecker checks that the primary can be reached.
+func PingChecker(client *mongo.Client) health.Checker {
+	return health.NewChecker("mongodb", func(ctx context.Context) error {
+		return client.Ping(ctx, readpref.Primary())
+	})
+}
+
+// IndexChecker checks that every index created by EnsureIndexes exists, as
+// some of them enforce invariants the repositories rely on.
+func IndexChecker(db *mongo.Database) health.Checker {
+	return health.NewChecker("mongodb_indexes", func(ctx context.Context) error {
+		existing := make(map[string]map[string]bool)
+		var missing []string
+		for _, index := range indexes {
+			keys, ok := existing[index.collection]
+			if !ok {
+				var err error
+				if keys, err = indexKeys(ctx, db.Collection(index.collection)); err != nil {
+					return fmt.Errorf("failed to list indexes on %s: %w", index.collection, err)
+				}
+				existing[index.collection] = keys
+			}
+
+			// Index keys are always declared as bson.D so their order is kept.
+			key := indexKey(index.model.Keys.(bson.D))
+			if !keys[key] {
+				missing = append(missing, index.collection+"."+key)
+			}
+		}
+
+		if len(missing) > 0 {
+			return fmt.Errorf("missing indexes: %s", strings.Join(missing, ", "))
+		}
+		return nil
+	})
+}
+
+// indexKeys returns the keys of every index on collection, as formatted by
+// indexKey.
+func indexKeys(ctx context.Context, collection *mongo.Collection) (map[string]bool, error) {
+	cursor, err := collection.Indexes().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var specs []struct {
+		Key bson.D `bson:"key"`
+	}
+	if err := cursor.All(ctx, &specs); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		keys[indexKey(spec.Key)] = true
+	}
+	return keys, nil
+}
+
+// indexKey formats index keys the way Mongo names indexes by default, e.g.
+// "movie_id_1_date_-1".
+func indexKey(keys bson.D) string {
+	parts := make([]string, 0, len(keys)*2)
+	for _, key := range keys {
+		parts = append(parts, key.Key, fmt.Sprint(key.Value))
+	}
+	return strings.Join(parts, "_")
+}
@@ -0,0 +1,81 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yasv98/movies-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type idempotencyRepository struct {
+	db *mongo.Database
+}
+
+func NewIdempotencyRepository(db *mongo.Database) domain.IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+func (r *idempotencyRepository) Create(ctx context.Context, request *domain.IdempotentRequest) error {
+	_, err := r.db.Collection("idempotency_keys").InsertOne(ctx, request)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrIdempotencyKeyExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+func (r *idempotencyRepository) Get(ctx context.Context, id string) (*domain.IdempotentRequest, error) {
+	var request domain.IdempotentRequest
+	err := r.db.Collection("idempotency_keys").FindOne(ctx, bson.M{"_id": id}).Decode(&request)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	return &request, nil
+}
+
+func (r *idempotencyRepository) Complete(ctx context.Context, id string, status int, contentType string, body []byte, expiresAt time.Time) error {
+	_, err := r.db.Collection("idempotency_keys").UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{
+			"status":       status,
+			"content_type": contentType,
+			"body":         body,
+			"expires_at":   expiresAt,
+		}},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+func (r *idempotencyRepository) Delete(ctx context.Context, id string) error {
+	if _, err := r.db.Collection("idempotency_keys").DeleteOne(ctx, bson.M{"_id": id}); err != nil {
+		return fmt.Errorf("failed to delete idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+func (r *idempotencyRepository) DeleteExpired(ctx context.Context, id string, now time.Time) error {
+	_, err := r.db.Collection("idempotency_keys").DeleteOne(ctx, bson.M{
+		"_id":        id,
+		"expires_at": bson.M{"$lte": now},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete expired idempotency key: %w", err)
+	}
+
+	return nil
+}
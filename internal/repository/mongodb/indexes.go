@@ -0,0 +1,125 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type collectionIndex struct {
+	collection string
+	model      mongo.IndexModel
+}
+
+// indexes lists the indexes the repositories rely on. Unique indexes here
+// enforce invariants such as one reaction per user per comment, so they
+// must exist before the API serves traffic.
+var indexes = []collectionIndex{
+	{
+		collection: "movies",
+		model:      mongo.IndexModel{Keys: bson.D{{Key: "title_normalized", Value: 1}}},
+	},
+	{
+		collection: "movies",
+		model:      mongo.IndexModel{Keys: bson.D{{Key: "directors_normalized", Value: 1}}},
+	},
+	{
+		collection: "movies",
+		model:      mongo.IndexModel{Keys: bson.D{{Key: "cast_normalized", Value: 1}}},
+	},
+	{
+		collection: "movies",
+		model:      mongo.IndexModel{Keys: bson.D{{Key: "genres", Value: 1}}},
+	},
+	{
+		collection: "movies",
+		model:      mongo.IndexModel{Keys: bson.D{{Key: "directors", Value: 1}}},
+	},
+	{
+		collection: "movies",
+		model:      mongo.IndexModel{Keys: bson.D{{Key: "cast", Value: 1}}},
+	},
+	{
+		collection: "movies",
+		model:      mongo.IndexModel{Keys: bson.D{{Key: "community_rating.average", Value: -1}, {Key: "community_rating.count", Value: -1}}},
+	},
+	{
+		collection: "comments",
+		model:      mongo.IndexModel{Keys: bson.D{{Key: "movie_id", Value: 1}, {Key: "parent_id", Value: 1}}},
+	},
+	{
+		collection: "comments",
+		model:      mongo.IndexModel{Keys: bson.D{{Key: "movie_id", Value: 1}, {Key: "reaction_count", Value: -1}}},
+	},
+	{
+		collection: "comments",
+		model:      mongo.IndexModel{Keys: bson.D{{Key: "status", Value: 1}, {Key: "flag_count", Value: -1}}},
+	},
+	{
+		collection: "comments",
+		model:      mongo.IndexModel{Keys: bson.D{{Key: "email", Value: 1}, {Key: "date", Value: -1}}},
+	},
+	{
+		collection: "comment_flags",
+		model: mongo.IndexModel{
+			Keys:    bson.D{{Key: "comment_id", Value: 1}, {Key: "user_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	},
+	{
+		collection: "ratings",
+		model: mongo.IndexModel{
+			Keys:    bson.D{{Key: "movie_id", Value: 1}, {Key: "user_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	},
+	{
+		collection: "user_lists",
+		model: mongo.IndexModel{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "list", Value: 1}, {Key: "movie_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	},
+	{
+		collection: "user_lists",
+		model:      mongo.IndexModel{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "list", Value: 1}, {Key: "position", Value: 1}}},
+	},
+	{
+		collection: "collections",
+		model:      mongo.IndexModel{Keys: bson.D{{Key: "visibility", Value: 1}, {Key: "updated_at", Value: -1}}},
+	},
+	{
+		collection: "collections",
+		model:      mongo.IndexModel{Keys: bson.D{{Key: "owner", Value: 1}, {Key: "updated_at", Value: -1}}},
+	},
+	{
+		collection: "comment_reactions",
+		model: mongo.IndexModel{
+			Keys:    bson.D{{Key: "comment_id", Value: 1}, {Key: "user_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	},
+	{
+		// Expired idempotency keys are removed by MongoDB's TTL monitor.
+		collection: "idempotency_keys",
+		model: mongo.IndexModel{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	},
+}
+
+// EnsureIndexes creates any missing indexes. Creating an index that already
+// exists is a no-op.
+func EnsureIndexes(ctx context.Context, db *mongo.Database) error {
+	for _, index := range indexes {
+		if _, err := db.Collection(index.collection).Indexes().CreateOne(ctx, index.model); err != nil {
+			return fmt.Errorf("failed to create index on %s: %w", index.collection, err)
+		}
+	}
+
+	return nil
+}
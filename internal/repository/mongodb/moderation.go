@@ -0,0 +1,141 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yasv98/movies-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// queueStatuses are the statuses of comments awaiting a moderator's
+// decision.
+var queueStatuses = bson.A{
+	domain.CommentStatusPending,
+	domain.CommentStatusFlagged,
+	domain.CommentStatusHidden,
+}
+
+type moderationRepository struct {
+	db *mongo.Database
+}
+
+func NewModerationRepository(db *mongo.Database) domain.ModerationRepository {
+	return &moderationRepository{db: db}
+}
+
+func (r *moderationRepository) AddFlag(ctx context.Context, movieID, commentID primitive.ObjectID, threshold int) error {
+	// Comments already hidden or reviewed keep their status, everything else
+	// is flagged until the threshold is reached and then hidden.
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"flag_count": bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$flag_count", 0}}, 1}},
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"status": bson.M{"$switch": bson.M{
+				"branches": bson.A{
+					bson.M{
+						"case": bson.M{"$in": bson.A{
+							bson.M{"$ifNull": bson.A{"$status", domain.CommentStatusVisible}},
+							bson.A{
+								domain.CommentStatusPending,
+								domain.CommentStatusHidden,
+								domain.CommentStatusApproved,
+								domain.CommentStatusRejected,
+							},
+						}},
+						"then": "$status",
+					},
+					bson.M{
+						"case": bson.M{"$gte": bson.A{"$flag_count", threshold}},
+						"then": domain.CommentStatusHidden,
+					},
+				},
+				"default": domain.CommentStatusFlagged,
+			}},
+		}}},
+	}
+
+	result, err := r.db.Collection("comments").UpdateOne(
+		ctx,
+		bson.M{
+			"_id":      commentID,
+			"movie_id": movieID,
+		},
+		update,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to flag comment: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrCommentNotFoundForMovie
+	}
+
+	return nil
+}
+
+func (r *moderationRepository) GetQueue(ctx context.Context, page, limit int) ([]domain.Comment, error) {
+	skip := (page - 1) * limit
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "flag_count", Value: -1}, {Key: "date", Value: 1}}).
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.db.Collection("comments").Find(ctx, bson.M{
+		"status": bson.M{"$in": queueStatuses},
+	}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find flagged comments: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var comments []domain.Comment
+	if err = cursor.All(ctx, &comments); err != nil {
+		return nil, err
+	}
+
+	return comments, nil
+}
+
+func (r *moderationRepository) Decide(ctx context.Context, commentID primitive.ObjectID, decision *domain.ModerationDecision) (*domain.Comment, error) {
+	decision.Date = primitive.NewDateTimeFromTime(time.Now())
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	// Only comments still in the queue are decided, so concurrent decisions
+	// cannot overwrite each other.
+	var comment domain.Comment
+	err := r.db.Collection("comments").FindOneAndUpdate(
+		ctx,
+		bson.M{
+			"_id":    commentID,
+			"status": bson.M{"$in": queueStatuses},
+		},
+		bson.M{"$set": bson.M{
+			"status":     decision.Status,
+			"moderation": decision,
+		}},
+		opts,
+	).Decode(&comment)
+	if err == mongo.ErrNoDocuments {
+		count, err := r.db.Collection("comments").CountDocuments(ctx, bson.M{"_id": commentID}, options.Count().SetLimit(1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to find comment: %w", err)
+		}
+		if count == 0 {
+			return nil, domain.ErrCommentNotFound
+		}
+		return nil, domain.ErrCommentNotInQueue
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to moderate comment: %w", err)
+	}
+
+	return &comment, nil
+}
@@ -2,6 +2,7 @@ package mongodb
 
 import (
 	"context"
+	"regexp"
 
 	"github.com/yasv98/movies-api/internal/domain"
 	"go.mongodb.org/mongo-driver/bson"
@@ -18,33 +19,234 @@ func NewMovieRepository(db *mongo.Database) domain.MovieRepository {
 	return &movieRepository{db: db}
 }
 
-func (r *movieRepository) GetMovie(ctx context.Context, id primitive.ObjectID) (*domain.Movie, error) {
+func (r *movieRepository) GetMovie(ctx context.Context, id primitive.ObjectID, fields []string) (*domain.Movie, error) {
+	opts := options.FindOne()
+	if projection := fieldProjection(fields); projection != nil {
+		opts.SetProjection(projection)
+	}
+
 	var movie domain.Movie
-	if err := r.db.Collection("movies").FindOne(ctx, bson.M{"_id": id}).Decode(&movie); err != nil {
+	if err := r.db.Collection("movies").FindOne(ctx, bson.M{"_id": id}, opts).Decode(&movie); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrMovieNotFound
+		}
 		return nil, err
 	}
 
 	return &movie, nil
 }
 
-func (r *movieRepository) GetMovies(ctx context.Context, titleFilter string, page, limit int) ([]domain.Movie, error) {
-	skip := (page - 1) * limit
+func (r *movieRepository) GetMovies(ctx context.Context, query domain.MovieQuery) ([]domain.Movie, error) {
+	skip := (query.Page - 1) * query.Limit
 
 	opts := options.Find().
 		SetSkip(int64(skip)).
-		SetLimit(int64(limit))
+		SetLimit(int64(query.Limit))
+	if sort := movieSort(query.Sort); sort != nil {
+		opts.SetSort(sort)
+	}
+	if projection := fieldProjection(query.Fields); projection != nil {
+		opts.SetProjection(projection)
+	}
 
-	filter := bson.M{}
-	if titleFilter != "" {
-		filter = bson.M{
-			"title": primitive.Regex{
-				Pattern: titleFilter,
-				Options: "i",
-			},
+	cursor, err := r.db.Collection("movies").Find(ctx, movieFilter(query), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var movies []domain.Movie
+	if err = cursor.All(ctx, &movies); err != nil {
+		return nil, err
+	}
+
+	return movies, nil
+}
+
+func (r *movieRepository) GetMoviesByIDs(ctx context.Context, ids []primitive.ObjectID, fields []string) ([]domain.Movie, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	opts := options.Find()
+	if projection := fieldProjection(fields); projection != nil {
+		opts.SetProjection(projection)
+	}
+
+	cursor, err := r.db.Collection("movies").Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var movies []domain.Movie
+	if err = cursor.All(ctx, &movies); err != nil {
+		return nil, err
+	}
+
+	return movies, nil
+}
+
+// maxFacetValues caps the values returned per facet, keeping the most
+// common.
+const maxFacetValues = 50
+
+func (r *movieRepository) GetMovieFacets(ctx context.Context, query domain.MovieQuery, facets []domain.MovieFacet) (domain.MovieFacets, error) {
+	if len(facets) == 0 {
+		return domain.MovieFacets{}, nil
+	}
+
+	facetStages := bson.M{}
+	for _, facet := range facets {
+		facetStages[string(facet)] = facetPipeline(facet)
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: movieFilter(query)}},
+		{{Key: "$facet", Value: facetStages}},
+	}
+
+	cursor, err := r.db.Collection("movies").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []domain.MovieFacets
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	result := domain.MovieFacets{}
+	if len(results) > 0 {
+		result = results[0]
+	}
+	// Facets with no matching movies are returned as empty rather than
+	// missing.
+	for _, facet := range facets {
+		if result[facet] == nil {
+			result[facet] = []domain.FacetCount{}
 		}
 	}
 
-	cursor, err := r.db.Collection("movies").Find(ctx, filter, opts)
+	return result, nil
+}
+
+// facetPipeline returns the stages counting movies by facet, most common
+// values first except for decades which are listed in order.
+func facetPipeline(facet domain.MovieFacet) bson.A {
+	countSort := bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}
+
+	switch facet {
+	case domain.MovieFacetDecade:
+		year := bson.M{"$toInt": "$year"}
+		return bson.A{
+			bson.M{"$match": bson.M{"year": bson.M{"$type": "number"}}},
+			bson.M{"$group": bson.M{
+				"_id":   bson.M{"$subtract": bson.A{year, bson.M{"$mod": bson.A{year, 10}}}},
+				"count": bson.M{"$sum": 1},
+			}},
+			bson.M{"$sort": bson.D{{Key: "_id", Value: 1}}},
+			bson.M{"$project": bson.M{
+				"_id":   bson.M{"$concat": bson.A{bson.M{"$toString": "$_id"}, "s"}},
+				"count": 1,
+			}},
+		}
+	case domain.MovieFacetRated:
+		return bson.A{
+			bson.M{"$match": bson.M{"rated": bson.M{"$nin": bson.A{nil, ""}}}},
+			bson.M{"$group": bson.M{"_id": "$rated", "count": bson.M{"$sum": 1}}},
+			bson.M{"$sort": countSort},
+			bson.M{"$limit": maxFacetValues},
+		}
+	default:
+		// The remaining facets are array fields, counted per element.
+		field := "$" + string(facet)
+		return bson.A{
+			bson.M{"$unwind": field},
+			bson.M{"$group": bson.M{"_id": field, "count": bson.M{"$sum": 1}}},
+			bson.M{"$sort": countSort},
+			bson.M{"$limit": maxFacetValues},
+		}
+	}
+}
+
+func (r *movieRepository) GetMovieSummaries(ctx context.Context, ids []primitive.ObjectID) ([]domain.MovieSummary, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	opts := options.Find().SetProjection(bson.M{
+		"title":   1,
+		"year":    1,
+		"genres":  1,
+		"runtime": 1,
+		"rated":   1,
+		"imdb":    1,
+		"poster":  1,
+	})
+
+	cursor, err := r.db.Collection("movies").Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var movies []domain.MovieSummary
+	if err = cursor.All(ctx, &movies); err != nil {
+		return nil, err
+	}
+
+	return movies, nil
+}
+
+func (r *movieRepository) GetSimilarCandidates(ctx context.Context, movie *domain.Movie, limit int) ([]domain.Movie, error) {
+	genres := nonNil(movie.Genres)
+	directors := nonNil(movie.Directors)
+	cast := nonNil(movie.Cast)
+
+	// overlapSize counts the values field shares with the target movie.
+	overlapSize := func(field string, values []string) bson.M {
+		return bson.M{"$size": bson.M{"$setIntersection": bson.A{
+			bson.M{"$ifNull": bson.A{"$" + field, bson.A{}}},
+			values,
+		}}}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"_id": bson.M{"$ne": movie.ID},
+			"$or": bson.A{
+				bson.M{"genres": bson.M{"$in": genres}},
+				bson.M{"directors": bson.M{"$in": directors}},
+				bson.M{"cast": bson.M{"$in": cast}},
+			},
+		}}},
+		// A rough overlap score to pick the most promising candidates, the
+		// final ranking is done by the caller.
+		{{Key: "$addFields", Value: bson.M{
+			"overlap": bson.M{"$add": bson.A{
+				overlapSize("genres", genres),
+				bson.M{"$multiply": bson.A{overlapSize("directors", directors), 3}},
+				overlapSize("cast", cast),
+			}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "overlap", Value: -1}, {Key: "imdb.rating", Value: -1}}}},
+		{{Key: "$limit", Value: limit}},
+		{{Key: "$project", Value: bson.M{
+			"title":     1,
+			"year":      1,
+			"genres":    1,
+			"runtime":   1,
+			"rated":     1,
+			"imdb":      1,
+			"poster":    1,
+			"directors": 1,
+			"cast":      1,
+		}}},
+	}
+
+	cursor, err := r.db.Collection("movies").Aggregate(ctx, pipeline)
 	if err != nil {
 		return nil, err
 	}
@@ -57,3 +259,79 @@ func (r *movieRepository) GetMovies(ctx context.Context, titleFilter string, pag
 
 	return movies, nil
 }
+
+func (r *movieRepository) Autocomplete(ctx context.Context, prefix string, limit int) ([]domain.MovieSuggestion, error) {
+	// An anchored, case sensitive regex on the normalized title can use its
+	// index as a range scan.
+	filter := bson.M{"title_normalized": primitive.Regex{Pattern: "^" + regexp.QuoteMeta(prefix)}}
+
+	opts := options.Find().
+		SetProjection(bson.M{"title": 1, "year": 1, "poster": 1}).
+		SetSort(bson.D{{Key: "title_normalized", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.db.Collection("movies").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var movies []domain.MovieSuggestion
+	if err = cursor.All(ctx, &movies); err != nil {
+		return nil, err
+	}
+
+	return movies, nil
+}
+
+// nonNil returns values, or an empty slice if it is nil, as Mongo rejects
+// null where an array is expected.
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+func movieFilter(query domain.MovieQuery) bson.M {
+	filter := bson.M{}
+	if query.Title != "" {
+		filter["title"] = primitive.Regex{
+			Pattern: query.Title,
+			Options: "i",
+		}
+	}
+	if query.MinCommunityRating > 0 {
+		filter["community_rating.average"] = bson.M{"$gte": query.MinCommunityRating}
+	}
+	if query.MinCommunityVotes > 0 {
+		filter["community_rating.count"] = bson.M{"$gte": query.MinCommunityVotes}
+	}
+
+	return filter
+}
+
+// fieldProjection includes only the given fields, or returns nil to include
+// every field.
+func fieldProjection(fields []string) bson.M {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	projection := make(bson.M, len(fields))
+	for _, field := range fields {
+		projection[field] = 1
+	}
+	return projection
+}
+
+func movieSort(sort domain.MovieSort) bson.D {
+	switch sort {
+	case domain.MovieSortCommunityRating:
+		return bson.D{
+			{Key: "community_rating.average", Value: -1},
+			{Key: "community_rating.count", Value: -1},
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/yasv98/movies-api/internal/logging"
+	"github.com/yasv98/movies-api/internal/textnorm"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const normalizeBatchSize = 500
+
+// SyncNormalizedFields keeps the normalized copies of movie fields used for
+// case and accent insensitive lookups in step with the originals. Movies are
+// only written when a normalized field is missing or out of date, so it is
+// cheap to run on every start.
+func SyncNormalizedFields(ctx context.Context, db *mongo.Database) error {
+	movies := db.Collection("movies")
+
+	opts := options.Find().SetProjection(bson.M{
+		"title":                1,
+		"title_normalized":     1,
+		"directors":            1,
+		"directors_normalized": 1,
+		"cast":                 1,
+		"cast_normalized":      1,
+	})
+
+	cursor, err := movies.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return fmt.Errorf("failed to find movies: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var models []mongo.WriteModel
+	var updated int
+	flush := func() error {
+		if len(models) == 0 {
+			return nil
+		}
+		if _, err := movies.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+			return fmt.Errorf("failed to update normalized fields: %w", err)
+		}
+		updated += len(models)
+		models = models[:0]
+		return nil
+	}
+
+	for cursor.Next(ctx) {
+		var movie struct {
+			ID                  primitive.ObjectID `bson:"_id"`
+			Title               string             `bson:"title"`
+			TitleNormalized     *string            `bson:"title_normalized"`
+			Directors           []string           `bson:"directors"`
+			DirectorsNormalized []string           `bson:"directors_normalized"`
+			Cast                []string           `bson:"cast"`
+			CastNormalized      []string           `bson:"cast_normalized"`
+		}
+		if err := cursor.Decode(&movie); err != nil {
+			return fmt.Errorf("failed to decode movie: %w", err)
+		}
+
+		set := bson.M{}
+		if title := textnorm.Fold(movie.Title); movie.TitleNormalized == nil || *movie.TitleNormalized != title {
+			set["title_normalized"] = title
+		}
+		// Names are folded position by position so each normalized name can
+		// be matched back to the original.
+		if directors := foldAll(movie.Directors); !slices.Equal(directors, movie.DirectorsNormalized) {
+			set["directors_normalized"] = directors
+		}
+		if cast := foldAll(movie.Cast); !slices.Equal(cast, movie.CastNormalized) {
+			set["cast_normalized"] = cast
+		}
+		if len(set) == 0 {
+			continue
+		}
+
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": movie.ID}).
+			SetUpdate(bson.M{"$set": set}))
+
+		if len(models) == normalizeBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("failed to read movies: %w", err)
+	}
+
+	if err := flush(); err != nil {
+		return err
+	}
+
+	logging.FromContext(ctx).Info("synced normalized movie fields", "updated", updated)
+	return nil
+}
+
+func foldAll(values []string) []string {
+	folded := make([]string, len(values))
+	for i, value := range values {
+		folded[i] = textnorm.Fold(value)
+	}
+	return folded
+}
@@ -0,0 +1,173 @@
+package mongodb
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/yasv98/movies-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type personRepository struct {
+	db *mongo.Database
+}
+
+func NewPersonRepository(db *mongo.Database) domain.PersonRepository {
+	return &personRepository{db: db}
+}
+
+func (r *personRepository) SearchPeople(ctx context.Context, query string, limit int) ([]domain.Person, error) {
+	// Matches the start of any word so people can be found by surname.
+	match := primitive.Regex{Pattern: `(^|\s)` + regexp.QuoteMeta(query)}
+
+	return r.people(ctx, match, limit)
+}
+
+func (r *personRepository) GetPerson(ctx context.Context, name string) (*domain.Person, error) {
+	people, err := r.people(ctx, name, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(people) == 0 {
+		return nil, domain.ErrPersonNotFound
+	}
+
+	return &people[0], nil
+}
+
+// people aggregates the directors and cast members whose normalized name
+// matches match, which may be a name or a regex.
+func (r *personRepository) people(ctx context.Context, match interface{}, limit int) ([]domain.Person, error) {
+	// credits pairs each of a movie's names in field with its normalized
+	// form, relying on SyncNormalizedFields keeping them in the same order.
+	credits := func(field string, role domain.PersonRole) bson.M {
+		return bson.M{"$map": bson.M{
+			"input": bson.M{"$zip": bson.M{"inputs": bson.A{
+				bson.M{"$ifNull": bson.A{"$" + field, bson.A{}}},
+				bson.M{"$ifNull": bson.A{"$" + field + "_normalized", bson.A{}}},
+			}}},
+			"as": "credit",
+			"in": bson.M{
+				"name":       bson.M{"$arrayElemAt": bson.A{"$$credit", 0}},
+				"normalized": bson.M{"$arrayElemAt": bson.A{"$$credit", 1}},
+				"role":       role,
+			},
+		}}
+	}
+	numeric := func(field string) bson.M {
+		return bson.M{"$cond": bson.A{bson.M{"$isNumber": field}, field, nil}}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"$or": bson.A{
+			bson.M{"directors_normalized": match},
+			bson.M{"cast_normalized": match},
+		}}}},
+		{{Key: "$project", Value: bson.M{
+			"year":   numeric("$year"),
+			"rating": numeric("$imdb.rating"),
+			"credits": bson.M{"$concatArrays": bson.A{
+				credits("directors", domain.PersonRoleDirector),
+				credits("cast", domain.PersonRoleCast),
+			}},
+		}}},
+		{{Key: "$unwind", Value: "$credits"}},
+		{{Key: "$match", Value: bson.M{"credits.normalized": match}}},
+		// Group by person and movie first so someone both directing and
+		// starring in a movie only counts it once.
+		{{Key: "$group", Value: bson.M{
+			"_id":    bson.M{"person": "$credits.normalized", "movie": "$_id"},
+			"name":   bson.M{"$first": "$credits.name"},
+			"roles":  bson.M{"$addToSet": "$credits.role"},
+			"year":   bson.M{"$first": "$year"},
+			"rating": bson.M{"$first": "$rating"},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":            "$_id.person",
+			"name":           bson.M{"$first": "$name"},
+			"roles":          bson.M{"$push": "$roles"},
+			"movies":         bson.M{"$sum": 1},
+			"first_year":     bson.M{"$min": "$year"},
+			"last_year":      bson.M{"$max": "$year"},
+			"average_rating": bson.M{"$avg": "$rating"},
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"roles": bson.M{"$reduce": bson.M{
+				"input":        "$roles",
+				"initialValue": bson.A{},
+				"in":           bson.M{"$setUnion": bson.A{"$$value", "$$this"}},
+			}},
+			"average_rating": bson.M{"$round": bson.A{"$average_rating", 2}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "movies", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$limit", Value: limit}},
+	}
+
+	cursor, err := r.db.Collection("movies").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var people []domain.Person
+	if err = cursor.All(ctx, &people); err != nil {
+		return nil, err
+	}
+
+	return people, nil
+}
+
+func (r *personRepository) GetPersonMovies(ctx context.Context, name string, sort domain.PersonMovieSort, page, limit int) ([]domain.PersonMovie, error) {
+	filter := bson.M{"$or": bson.A{
+		bson.M{"directors_normalized": name},
+		bson.M{"cast_normalized": name},
+	}}
+
+	// roleIf adds role to the movie's roles if name is in field.
+	roleIf := func(field string, role domain.PersonRole) bson.M {
+		return bson.M{"$cond": bson.A{
+			bson.M{"$in": bson.A{name, bson.M{"$ifNull": bson.A{"$" + field, bson.A{}}}}},
+			bson.A{role},
+			bson.A{},
+		}}
+	}
+
+	sortBy := bson.D{{Key: "year", Value: 1}, {Key: "_id", Value: 1}}
+	if sort == domain.PersonMovieSortRating {
+		sortBy = bson.D{{Key: "imdb.rating", Value: -1}, {Key: "_id", Value: 1}}
+	}
+
+	opts := options.Find().
+		SetSort(sortBy).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetProjection(bson.M{
+			"title":   1,
+			"year":    1,
+			"genres":  1,
+			"runtime": 1,
+			"rated":   1,
+			"imdb":    1,
+			"poster":  1,
+			"roles": bson.M{"$concatArrays": bson.A{
+				roleIf("directors_normalized", domain.PersonRoleDirector),
+				roleIf("cast_normalized", domain.PersonRoleCast),
+			}},
+		})
+
+	cursor, err := r.db.Collection("movies").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var movies []domain.PersonMovie
+	if err = cursor.All(ctx, &movies); err != nil {
+		return nil, err
+	}
+
+	return movies, nil
+}
@@ -0,0 +1,126 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/yasv98/movies-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ratingRepository struct {
+	db *mongo.Database
+}
+
+func NewRatingRepository(db *mongo.Database) domain.RatingRepository {
+	return &ratingRepository{db: db}
+}
+
+func (r *ratingRepository) Set(ctx context.Context, rating *domain.Rating) error {
+	rating.Date = primitive.NewDateTimeFromTime(time.Now())
+
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After)
+
+	err := r.db.Collection("ratings").FindOneAndUpdate(
+		ctx,
+		bson.M{
+			"movie_id": rating.MovieID,
+			"user_id":  rating.UserID,
+		},
+		bson.M{
+			"$set": bson.M{
+				"score": rating.Score,
+				"date":  rating.Date,
+			},
+			"$setOnInsert": bson.M{
+				"_id": primitive.NewObjectID(),
+			},
+		},
+		opts,
+	).Decode(rating)
+	if err != nil {
+		return fmt.Errorf("failed to set rating: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ratingRepository) Get(ctx context.Context, movieID primitive.ObjectID, userID string) (*domain.Rating, error) {
+	var rating domain.Rating
+	if err := r.db.Collection("ratings").FindOne(ctx, bson.M{
+		"movie_id": movieID,
+		"user_id":  userID,
+	}).Decode(&rating); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrRatingNotFound
+		}
+		return nil, err
+	}
+
+	return &rating, nil
+}
+
+func (r *ratingRepository) Delete(ctx context.Context, movieID primitive.ObjectID, userID string) error {
+	result, err := r.db.Collection("ratings").DeleteOne(ctx, bson.M{
+		"movie_id": movieID,
+		"user_id":  userID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete rating: %w", err)
+	}
+
+	if result.DeletedCount == 0 {
+		return domain.ErrRatingNotFound
+	}
+
+	return nil
+}
+
+func (r *ratingRepository) RefreshCommunityRating(ctx context.Context, movieID primitive.ObjectID) error {
+	cursor, err := r.db.Collection("ratings").Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"movie_id": movieID}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$score",
+			"count": bson.M{"$sum": 1},
+		}}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to aggregate ratings: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var scores []struct {
+		Score int `bson:"_id"`
+		Count int `bson:"count"`
+	}
+	if err := cursor.All(ctx, &scores); err != nil {
+		return err
+	}
+
+	update := bson.M{"$unset": bson.M{"community_rating": ""}}
+	if len(scores) > 0 {
+		rating := domain.CommunityRating{Histogram: make(map[string]int, len(scores))}
+		sum := 0
+		for _, s := range scores {
+			rating.Histogram[strconv.Itoa(s.Score)] = s.Count
+			rating.Count += s.Count
+			sum += s.Score * s.Count
+		}
+		rating.Average = math.Round(float64(sum)/float64(rating.Count)*100) / 100
+		update = bson.M{"$set": bson.M{"community_rating": rating}}
+	}
+
+	if _, err := r.db.Collection("movies").UpdateOne(ctx, bson.M{"_id": movieID}, update); err != nil {
+		return fmt.Errorf("failed to update community rating: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yasv98/movies-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type reactionRepository struct {
+	db *mongo.Database
+}
+
+func NewReactionRepository(db *mongo.Database) domain.ReactionRepository {
+	return &reactionRepository{db: db}
+}
+
+func (r *reactionRepository) Set(ctx context.Context, reaction *domain.Reaction) error {
+	reaction.Date = primitive.NewDateTimeFromTime(time.Now())
+
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.Before)
+
+	filter := bson.M{
+		"comment_id": reaction.CommentID,
+		"user_id":    reaction.UserID,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"movie_id": reaction.MovieID,
+			"type":     reaction.Type,
+			"date":     reaction.Date,
+		},
+		"$setOnInsert": bson.M{
+			"_id": primitive.NewObjectID(),
+		},
+	}
+
+	var previous domain.Reaction
+	err := r.db.Collection("comment_reactions").FindOneAndUpdate(ctx, filter, update, opts).Decode(&previous)
+	if mongo.IsDuplicateKeyError(err) {
+		// A concurrent request from the same user inserted the reaction
+		// first, so this attempt updates it instead.
+		err = r.db.Collection("comment_reactions").FindOneAndUpdate(ctx, filter, update, opts).Decode(&previous)
+	}
+
+	switch {
+	case err == mongo.ErrNoDocuments:
+		// First reaction from this user.
+		return r.incrementCounts(ctx, reaction.CommentID, bson.M{
+			"reactions." + string(reaction.Type): 1,
+			"reaction_count":                     1,
+		})
+	case err != nil:
+		return fmt.Errorf("failed to set reaction: %w", err)
+	case previous.Type == reaction.Type:
+		// Reacting twice with the same type is a no-op.
+		return nil
+	default:
+		// The user switched reaction type, so move their count across.
+		return r.incrementCounts(ctx, reaction.CommentID, bson.M{
+			"reactions." + string(previous.Type): -1,
+			"reactions." + string(reaction.Type): 1,
+		})
+	}
+}
+
+func (r *reactionRepository) Remove(ctx context.Context, commentID primitive.ObjectID, userID string) error {
+	var removed domain.Reaction
+	err := r.db.Collection("comment_reactions").FindOneAndDelete(ctx, bson.M{
+		"comment_id": commentID,
+		"user_id":    userID,
+	}).Decode(&removed)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to remove reaction: %w", err)
+	}
+
+	return r.incrementCounts(ctx, commentID, bson.M{
+		"reactions." + string(removed.Type): -1,
+		"reaction_count":                    -1,
+	})
+}
+
+func (r *reactionRepository) incrementCounts(ctx context.Context, commentID primitive.ObjectID, inc bson.M) error {
+	_, err := r.db.Collection("comments").UpdateOne(
+		ctx,
+		bson.M{"_id": commentID},
+		bson.M{"$inc": inc},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update reaction counts: %w", err)
+	}
+
+	return nil
+}
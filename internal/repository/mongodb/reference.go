@@ -0,0 +1,54 @@
+package mongodb
+
+import (
+	"context"
+
+	"github.com/yasv98/movies-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// referenceFields maps each reference kind to the movie field holding it.
+var referenceFields = map[domain.ReferenceKind]string{
+	domain.ReferenceGenres:    "genres",
+	domain.ReferenceCountries: "countries",
+	domain.ReferenceLanguages: "languages",
+	domain.ReferenceRatings:   "rated",
+}
+
+type referenceRepository struct {
+	db *mongo.Database
+}
+
+func NewReferenceRepository(db *mongo.Database) domain.ReferenceRepository {
+	return &referenceRepository{db: db}
+}
+
+func (r *referenceRepository) GetReferenceValues(ctx context.Context, kind domain.ReferenceKind) ([]domain.ReferenceValue, error) {
+	field, ok := referenceFields[kind]
+	if !ok {
+		return nil, domain.ErrInvalidReferenceKind
+	}
+
+	// Unwinding a field that is not an array leaves it as is, so the same
+	// pipeline counts both array and single valued fields.
+	pipeline := mongo.Pipeline{
+		{{Key: "$unwind", Value: "$" + field}},
+		{{Key: "$match", Value: bson.M{field: bson.M{"$type": "string", "$ne": ""}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$" + field, "count": bson.M{"$sum": 1}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+	}
+
+	cursor, err := r.db.Collection("movies").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var values []domain.ReferenceValue
+	if err = cursor.All(ctx, &values); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
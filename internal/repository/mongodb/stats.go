@@ -0,0 +1,150 @@
+package mongodb
+
+import (
+	"context"
+
+	"github.com/yasv98/movies-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type statsRepository struct {
+	db *mongo.Database
+}
+
+func NewStatsRepository(db *mongo.Database) domain.StatsRepository {
+	return &statsRepository{db: db}
+}
+
+// Some movies in the sample data have their year or rating stored as a
+// string, so only numeric values are aggregated.
+var (
+	hasNumericYear   = bson.M{"year": bson.M{"$type": "number"}}
+	hasNumericRating = bson.M{"imdb.rating": bson.M{"$type": "number"}}
+)
+
+func (r *statsRepository) MoviesPerYear(ctx context.Context) ([]domain.YearCount, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: hasNumericYear}},
+		{{Key: "$group", Value: bson.M{"_id": bson.M{"$toInt": "$year"}, "count": bson.M{"$sum": 1}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	}
+
+	var counts []domain.YearCount
+	if err := r.aggregate(ctx, "movies", pipeline, &counts); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+func (r *statsRepository) GenreDistribution(ctx context.Context) ([]domain.GenreCount, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$unwind", Value: "$genres"}},
+		{{Key: "$group", Value: bson.M{"_id": "$genres", "count": bson.M{"$sum": 1}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+	}
+
+	var counts []domain.GenreCount
+	if err := r.aggregate(ctx, "movies", pipeline, &counts); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+func (r *statsRepository) RatingsByGenreDecade(ctx context.Context) ([]domain.GenreDecadeRating, error) {
+	year := bson.M{"$toInt": "$year"}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"$and": bson.A{hasNumericYear, hasNumericRating}}}},
+		{{Key: "$unwind", Value: "$genres"}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"genre":  "$genres",
+				"decade": bson.M{"$subtract": bson.A{year, bson.M{"$mod": bson.A{year, 10}}}},
+			},
+			"average_rating": bson.M{"$avg": "$imdb.rating"},
+			"movies":         bson.M{"$sum": 1},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":            0,
+			"genre":          "$_id.genre",
+			"decade":         "$_id.decade",
+			"average_rating": bson.M{"$round": bson.A{"$average_rating", 2}},
+			"movies":         1,
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "genre", Value: 1}, {Key: "decade", Value: 1}}}},
+	}
+
+	var ratings []domain.GenreDecadeRating
+	if err := r.aggregate(ctx, "movies", pipeline, &ratings); err != nil {
+		return nil, err
+	}
+
+	return ratings, nil
+}
+
+func (r *statsRepository) TopDirectors(ctx context.Context, query domain.TopDirectorsQuery) ([]domain.DirectorRating, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: hasNumericRating}},
+		{{Key: "$unwind", Value: "$directors"}},
+		{{Key: "$group", Value: bson.M{
+			"_id":            "$directors",
+			"average_rating": bson.M{"$avg": "$imdb.rating"},
+			"movies":         bson.M{"$sum": 1},
+			"votes":          bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$isNumber": "$imdb.votes"}, "$imdb.votes", 0}}},
+		}}},
+		{{Key: "$match", Value: bson.M{"votes": bson.M{"$gte": query.MinVotes}}}},
+		{{Key: "$set", Value: bson.M{"average_rating": bson.M{"$round": bson.A{"$average_rating", 2}}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "average_rating", Value: -1}, {Key: "votes", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$limit", Value: query.Limit}},
+	}
+
+	var directors []domain.DirectorRating
+	if err := r.aggregate(ctx, "movies", pipeline, &directors); err != nil {
+		return nil, err
+	}
+
+	return directors, nil
+}
+
+func (r *statsRepository) CommentActivity(ctx context.Context, query domain.CommentActivityQuery) ([]domain.CommentActivity, error) {
+	date := bson.M{}
+	if !query.From.IsZero() {
+		date["$gte"] = query.From
+	}
+	if !query.To.IsZero() {
+		date["$lt"] = query.To
+	}
+	filter := visible(bson.M{})
+	if len(date) > 0 {
+		filter["date"] = date
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"$dateTrunc": bson.M{"date": "$date", "unit": string(query.Interval)}},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	}
+
+	var activity []domain.CommentActivity
+	if err := r.aggregate(ctx, "comments", pipeline, &activity); err != nil {
+		return nil, err
+	}
+
+	return activity, nil
+}
+
+func (r *statsRepository) aggregate(ctx context.Context, collection string, pipeline mongo.Pipeline, results interface{}) error {
+	cursor, err := r.db.Collection(collection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	return cursor.All(ctx, results)
+}
@@ -0,0 +1,182 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yasv98/movies-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type userListRepository struct {
+	db *mongo.Database
+}
+
+func NewUserListRepository(db *mongo.Database) domain.UserListRepository {
+	return &userListRepository{db: db}
+}
+
+func (r *userListRepository) Add(ctx context.Context, entry *domain.UserListEntry) error {
+	// Append to the end of the list.
+	position, err := r.nextPosition(ctx, entry.UserID, entry.List)
+	if err != nil {
+		return err
+	}
+	entry.Position = position
+
+	entry.ID = primitive.NewObjectID()
+	entry.AddedAt = primitive.NewDateTimeFromTime(time.Now())
+
+	if _, err := r.db.Collection("user_lists").InsertOne(ctx, entry); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrMovieAlreadyInList
+		}
+		return fmt.Errorf("failed to add movie to list: %w", err)
+	}
+
+	return nil
+}
+
+// nextPosition allocates the position after the end of a list. Positions
+// come from a counter per list incremented atomically, so concurrent adds
+// never share one. Reordering only assigns positions below the number of
+// entries, which the counter never falls behind.
+func (r *userListRepository) nextPosition(ctx context.Context, userID string, list domain.UserListKind) (int, error) {
+	counters := r.db.Collection("user_list_counters")
+	id := bson.D{{Key: "user_id", Value: userID}, {Key: "list", Value: list}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var counter struct {
+		Next int `bson:"next"`
+	}
+	err := counters.FindOneAndUpdate(ctx, bson.M{"_id": id}, bson.M{"$inc": bson.M{"next": 1}}, opts).Decode(&counter)
+	if err == nil {
+		return counter.Next - 1, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return 0, fmt.Errorf("failed to allocate list position: %w", err)
+	}
+
+	// Lists created before the counter existed continue after their last
+	// entry.
+	var last domain.UserListEntry
+	start := 0
+	err = r.db.Collection("user_lists").FindOne(
+		ctx,
+		bson.M{"user_id": userID, "list": list},
+		options.FindOne().SetSort(bson.D{{Key: "position", Value: -1}}),
+	).Decode(&last)
+	switch {
+	case err == mongo.ErrNoDocuments:
+	case err != nil:
+		return 0, fmt.Errorf("failed to find end of list: %w", err)
+	default:
+		start = last.Position + 1
+	}
+
+	// Whichever concurrent add creates the counter seeds it, the others
+	// increment it.
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"next": bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$next", start}}, 1}},
+	}}}}
+	opts.SetUpsert(true)
+	err = counters.FindOneAndUpdate(ctx, bson.M{"_id": id}, update, opts).Decode(&counter)
+	if mongo.IsDuplicateKeyError(err) {
+		// Another add created the counter between the two updates.
+		err = counters.FindOneAndUpdate(ctx, bson.M{"_id": id}, update, opts).Decode(&counter)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to allocate list position: %w", err)
+	}
+
+	return counter.Next - 1, nil
+}
+
+func (r *userListRepository) Remove(ctx context.Context, userID string, list domain.UserListKind, movieID primitive.ObjectID) error {
+	result, err := r.db.Collection("user_lists").DeleteOne(ctx, bson.M{
+		"user_id":  userID,
+		"list":     list,
+		"movie_id": movieID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove movie from list: %w", err)
+	}
+
+	if result.DeletedCount == 0 {
+		return domain.ErrMovieNotInList
+	}
+
+	return nil
+}
+
+func (r *userListRepository) List(ctx context.Context, userID string, list domain.UserListKind, page, limit int) ([]domain.UserListEntry, error) {
+	skip := (page - 1) * limit
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "position", Value: 1}, {Key: "added_at", Value: 1}}).
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.db.Collection("user_lists").Find(ctx, bson.M{
+		"user_id": userID,
+		"list":    list,
+	}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find list: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var entries []domain.UserListEntry
+	if err = cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r *userListRepository) Reorder(ctx context.Context, userID string, list domain.UserListKind, movieIDs []primitive.ObjectID) error {
+	filter := bson.M{"user_id": userID, "list": list}
+
+	current, err := r.db.Collection("user_lists").Distinct(ctx, "movie_id", filter)
+	if err != nil {
+		return fmt.Errorf("failed to find list: %w", err)
+	}
+
+	// The new order must be a permutation of the current list.
+	if len(current) != len(movieIDs) {
+		return domain.ErrInvalidListOrder
+	}
+	inList := make(map[primitive.ObjectID]bool, len(current))
+	for _, id := range current {
+		if oid, ok := id.(primitive.ObjectID); ok {
+			inList[oid] = true
+		}
+	}
+	for _, id := range movieIDs {
+		if !inList[id] {
+			return domain.ErrInvalidListOrder
+		}
+		delete(inList, id)
+	}
+
+	if len(movieIDs) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(movieIDs))
+	for position, movieID := range movieIDs {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"user_id": userID, "list": list, "movie_id": movieID}).
+			SetUpdate(bson.M{"$set": bson.M{"position": position}}))
+	}
+
+	if _, err := r.db.Collection("user_lists").BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+		return fmt.Errorf("failed to reorder list: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/yasv98/movies-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CollectionService struct {
+	collectionRepo domain.CollectionRepository
+	movieRepo      domain.MovieRepository
+}
+
+func NewCollectionService(collectionRepo domain.CollectionRepository, movieRepo domain.MovieRepository) *CollectionService {
+	return &CollectionService{
+		collectionRepo: collectionRepo,
+		movieRepo:      movieRepo,
+	}
+}
+
+func (s *CollectionService) CreateCollection(ctx context.Context, userID string, collection *domain.Collection) error {
+	collection.Owner = userID
+	if err := s.validate(ctx, collection); err != nil {
+		return err
+	}
+
+	return s.collectionRepo.Create(ctx, collection)
+}
+
+// UpdateCollection replaces the collection's editable fields. Only the
+// owner can update a collection.
+func (s *CollectionService) UpdateCollection(ctx context.Context, userID string, collection *domain.Collection) error {
+	existing, err := s.ownedCollection(ctx, userID, collection.ID)
+	if err != nil {
+		return err
+	}
+
+	collection.Owner = existing.Owner
+	collection.CreatedAt = existing.CreatedAt
+	if err := s.validate(ctx, collection); err != nil {
+		return err
+	}
+
+	return s.collectionRepo.Update(ctx, collection)
+}
+
+func (s *CollectionService) DeleteCollection(ctx context.Context, userID string, id primitive.ObjectID) error {
+	if _, err := s.ownedCollection(ctx, userID, id); err != nil {
+		return err
+	}
+
+	return s.collectionRepo.Delete(ctx, id)
+}
+
+// GetCollection returns the collection with its movies resolved in a single
+// query. Private collections are only visible to their owner.
+func (s *CollectionService) GetCollection(ctx context.Context, userID string, id primitive.ObjectID) (*domain.CollectionWithMovies, error) {
+	collection, err := s.collectionRepo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !collection.VisibleTo(userID) {
+		return nil, domain.ErrCollectionNotFound
+	}
+
+	byID, err := movieSummariesByID(ctx, s.movieRepo, collection.MovieIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	movies := make([]domain.MovieSummary, 0, len(collection.MovieIDs))
+	for _, movieID := range collection.MovieIDs {
+		if movie, ok := byID[movieID]; ok {
+			movies = append(movies, *movie)
+		}
+	}
+
+	return &domain.CollectionWithMovies{
+		Collection: *collection,
+		Movies:     movies,
+	}, nil
+}
+
+func (s *CollectionService) ListPublicCollections(ctx context.Context, page, limit int) ([]domain.Collection, error) {
+	return s.collectionRepo.ListPublic(ctx, page, limit)
+}
+
+func (s *CollectionService) ListUserCollections(ctx context.Context, userID string, page, limit int) ([]domain.Collection, error) {
+	return s.collectionRepo.ListByOwner(ctx, userID, page, limit)
+}
+
+// ownedCollection fetches a collection the user is about to change. Private
+// collections owned by someone else are reported as not found so their
+// existence is not revealed.
+func (s *CollectionService) ownedCollection(ctx context.Context, userID string, id primitive.ObjectID) (*domain.Collection, error) {
+	collection, err := s.collectionRepo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !collection.VisibleTo(userID) {
+		return nil, domain.ErrCollectionNotFound
+	}
+	if collection.Owner != userID {
+		return nil, domain.ErrNotCollectionOwner
+	}
+
+	return collection, nil
+}
+
+func (s *CollectionService) validate(ctx context.Context, collection *domain.Collection) error {
+	collection.Title = strings.TrimSpace(collection.Title)
+	if collection.Title == "" || len(collection.Title) > domain.MaxCollectionTitleLength {
+		return domain.ErrInvalidCollectionTitle
+	}
+
+	if len(collection.Description) > domain.MaxCollectionDescriptionLength {
+		return domain.ErrInvalidCollectionDescription
+	}
+
+	if collection.Visibility == "" {
+		collection.Visibility = domain.CollectionPrivate
+	}
+	switch collection.Visibility {
+	case domain.CollectionPrivate, domain.CollectionUnlisted, domain.CollectionPublic:
+	default:
+		return domain.ErrInvalidCollectionVisibility
+	}
+
+	if collection.MovieIDs == nil {
+		collection.MovieIDs = []primitive.ObjectID{}
+	}
+	if len(collection.MovieIDs) > domain.MaxCollectionMovies {
+		return domain.ErrInvalidCollectionMovies
+	}
+	seen := make(map[primitive.ObjectID]bool, len(collection.MovieIDs))
+	for _, id := range collection.MovieIDs {
+		if seen[id] {
+			return domain.ErrInvalidCollectionMovies
+		}
+		seen[id] = true
+	}
+
+	byID, err := movieSummariesByID(ctx, s.movieRepo, collection.MovieIDs)
+	if err != nil {
+		return err
+	}
+	if len(byID) != len(collection.MovieIDs) {
+		return domain.ErrMovieNotFound
+	}
+
+	return nil
+}
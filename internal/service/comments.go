@@ -2,37 +2,264 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"sync"
 
 	"github.com/yasv98/movies-api/internal/domain"
+	"github.com/yasv98/movies-api/internal/logging"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type CommentService struct {
-	commentRepo domain.CommentRepository
+	commentRepo  domain.CommentRepository
+	reactionRepo domain.ReactionRepository
+
+	mu     sync.RWMutex
+	filter ContentFilter
 }
 
-func NewCommentService(commentRepo domain.CommentRepository) *CommentService {
+// NewCommentService creates a CommentService. New and edited comments are
+// checked against filter, which may be nil to accept everything.
+func NewCommentService(
+	commentRepo domain.CommentRepository,
+	reactionRepo domain.ReactionRepository,
+	filter ContentFilter,
+) *CommentService {
 	return &CommentService{
-		commentRepo: commentRepo,
+		commentRepo:  commentRepo,
+		reactionRepo: reactionRepo,
+		filter:       filter,
 	}
 }
 
 func (c *CommentService) CreateComment(ctx context.Context, comment *domain.Comment) error {
+	ctx, span := tracer.Start(ctx, "CommentService.CreateComment")
+	defer span.End()
+
+	// Counts and moderation state are maintained by the repositories, never
+	// by clients.
+	comment.ReplyCount = 0
+	comment.Reactions = nil
+	comment.ReactionCount = 0
+	comment.Status = domain.CommentStatusVisible
+	comment.FlagCount = 0
+	comment.Moderation = nil
+
+	if comment.ParentID != nil {
+		_, err := c.commentRepo.GetMovieComment(ctx, comment.MovieID, *comment.ParentID)
+		if err == domain.ErrCommentNotFoundForMovie {
+			return domain.ErrParentCommentNotFound
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := c.applyFilter(ctx, comment); err != nil {
+		return err
+	}
+
 	return c.commentRepo.Create(ctx, comment)
 }
 
 func (c *CommentService) UpdateComment(ctx context.Context, comment *domain.Comment) error {
+	ctx, span := tracer.Start(ctx, "CommentService.UpdateComment")
+	defer span.End()
+
+	comment.Status = domain.CommentStatusVisible
+	if err := c.applyFilter(ctx, comment); err != nil {
+		return err
+	}
+
 	return c.commentRepo.Update(ctx, comment)
 }
 
+// SetFilter replaces the content filter checked by subsequent creates and
+// edits. A nil filter accepts everything.
+func (c *CommentService) SetFilter(filter ContentFilter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.filter = filter
+}
+
+// applyFilter runs the content filter over the comment, returning
+// ErrCommentRejected if it must not be written and marking it pending if it
+// is held for moderation.
+func (c *CommentService) applyFilter(ctx context.Context, comment *domain.Comment) error {
+	c.mu.RLock()
+	filter := c.filter
+	c.mu.RUnlock()
+
+	if filter == nil {
+		return nil
+	}
+
+	result, err := filter.Check(ctx, comment)
+	if err != nil {
+		return err
+	}
+
+	switch result.Action {
+	case FilterReject:
+		logging.FromContext(ctx).Info("comment rejected", "movie_id", comment.MovieID.Hex(), "reason", result.Reason)
+		return fmt.Errorf("%w: %s", domain.ErrCommentRejected, result.Reason)
+	case FilterHold:
+		logging.FromContext(ctx).Info("comment held for moderation", "movie_id", comment.MovieID.Hex(), "reason", result.Reason)
+		comment.Status = domain.CommentStatusPending
+	}
+
+	return nil
+}
+
 func (c *CommentService) DeleteComment(ctx context.Context, movieID, commentID primitive.ObjectID) error {
+	ctx, span := tracer.Start(ctx, "CommentService.DeleteComment")
+	defer span.End()
+
 	return c.commentRepo.Delete(ctx, movieID, commentID)
 }
 
 func (c *CommentService) GetMovieComment(ctx context.Context, movieID, commentID primitive.ObjectID) (*domain.Comment, error) {
+	ctx, span := tracer.Start(ctx, "CommentService.GetMovieComment")
+	defer span.End()
+
+	return c.commentRepo.GetMovieComment(ctx, movieID, commentID)
+}
+
+func (c *CommentService) GetMovieComments(ctx context.Context, movieID primitive.ObjectID, sort domain.CommentSort) ([]domain.Comment, error) {
+	ctx, span := tracer.Start(ctx, "CommentService.GetMovieComments")
+	defer span.End()
+
+	if !validCommentSort(sort) {
+		return nil, domain.ErrInvalidCommentSort
+	}
+
+	return c.commentRepo.GetMovieComments(ctx, movieID, sort)
+}
+
+// GetMovieCommentThread returns a movie's comments arranged into threads
+// using the given view. Sibling comments are ordered by sort.
+func (c *CommentService) GetMovieCommentThread(ctx context.Context, movieID primitive.ObjectID, view domain.CommentView, sort domain.CommentSort) ([]domain.CommentNode, error) {
+	ctx, span := tracer.Start(ctx, "CommentService.GetMovieCommentThread")
+	defer span.End()
+
+	if view != domain.CommentViewTree && view != domain.CommentViewFlat {
+		return nil, domain.ErrInvalidCommentView
+	}
+	if !validCommentSort(sort) {
+		return nil, domain.ErrInvalidCommentSort
+	}
+
+	comments, err := c.commentRepo.GetMovieComments(ctx, movieID, sort)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := buildCommentTree(comments)
+	if view == domain.CommentViewFlat {
+		return flattenCommentTree(tree), nil
+	}
+
+	return tree, nil
+}
+
+func (c *CommentService) GetCommentReplies(ctx context.Context, movieID, commentID primitive.ObjectID) ([]domain.Comment, error) {
+	ctx, span := tracer.Start(ctx, "CommentService.GetCommentReplies")
+	defer span.End()
+
+	if _, err := c.commentRepo.GetMovieComment(ctx, movieID, commentID); err != nil {
+		return nil, err
+	}
+
+	return c.commentRepo.GetCommentReplies(ctx, movieID, commentID)
+}
+
+// React sets the user's reaction to a comment and returns the comment with
+// its updated counts.
+func (c *CommentService) React(ctx context.Context, movieID, commentID primitive.ObjectID, userID string, reactionType domain.ReactionType) (*domain.Comment, error) {
+	ctx, span := tracer.Start(ctx, "CommentService.React")
+	defer span.End()
+
+	if !reactionType.Valid() {
+		return nil, domain.ErrInvalidReactionType
+	}
+
+	if _, err := c.commentRepo.GetMovieComment(ctx, movieID, commentID); err != nil {
+		return nil, err
+	}
+
+	if err := c.reactionRepo.Set(ctx, &domain.Reaction{
+		CommentID: commentID,
+		MovieID:   movieID,
+		UserID:    userID,
+		Type:      reactionType,
+	}); err != nil {
+		return nil, err
+	}
+
 	return c.commentRepo.GetMovieComment(ctx, movieID, commentID)
 }
 
-func (c *CommentService) GetMovieComments(ctx context.Context, movieID primitive.ObjectID) ([]domain.Comment, error) {
-	return c.commentRepo.GetMovieComments(ctx, movieID)
+// RemoveReaction removes the user's reaction to a comment, if any.
+func (c *CommentService) RemoveReaction(ctx context.Context, movieID, commentID primitive.ObjectID, userID string) error {
+	ctx, span := tracer.Start(ctx, "CommentService.RemoveReaction")
+	defer span.End()
+
+	if _, err := c.commentRepo.GetMovieComment(ctx, movieID, commentID); err != nil {
+		return err
+	}
+
+	return c.reactionRepo.Remove(ctx, commentID, userID)
+}
+
+func validCommentSort(sort domain.CommentSort) bool {
+	return sort == domain.CommentSortDefault || sort == domain.CommentSortTop
+}
+
+// buildCommentTree nests comments under their parents, preserving the order
+// they were given in. Replies whose parent no longer exists are promoted to
+// the top level so they are not lost.
+func buildCommentTree(comments []domain.Comment) []domain.CommentNode {
+	present := make(map[primitive.ObjectID]bool, len(comments))
+	for _, comment := range comments {
+		present[comment.ID] = true
+	}
+
+	var roots []domain.Comment
+	children := make(map[primitive.ObjectID][]domain.Comment)
+	for _, comment := range comments {
+		if comment.ParentID == nil || !present[*comment.ParentID] {
+			roots = append(roots, comment)
+			continue
+		}
+		children[*comment.ParentID] = append(children[*comment.ParentID], comment)
+	}
+
+	var build func(comments []domain.Comment, depth int) []domain.CommentNode
+	build = func(comments []domain.Comment, depth int) []domain.CommentNode {
+		nodes := make([]domain.CommentNode, 0, len(comments))
+		for _, comment := range comments {
+			nodes = append(nodes, domain.CommentNode{
+				Comment: comment,
+				Depth:   depth,
+				Replies: build(children[comment.ID], depth+1),
+			})
+		}
+		return nodes
+	}
+
+	return build(roots, 0)
+}
+
+// flattenCommentTree walks the tree depth first, returning every comment
+// directly after its parent.
+func flattenCommentTree(tree []domain.CommentNode) []domain.CommentNode {
+	flat := make([]domain.CommentNode, 0, len(tree))
+	for _, node := range tree {
+		replies := node.Replies
+		node.Replies = nil
+		flat = append(flat, node)
+		flat = append(flat, flattenCommentTree(replies)...)
+	}
+
+	return flat
 }
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/yasv98/movies-api/internal/domain"
+)
+
+// FilterAction is what a ContentFilter decides to do with a comment. Actions
+// are ordered by severity so the strictest one wins in a chain.
+type FilterAction int
+
+const (
+	FilterAllow FilterAction = iota
+	FilterHold
+	FilterReject
+)
+
+// ParseFilterAction converts a configured action name into a FilterAction.
+func ParseFilterAction(action string) (FilterAction, error) {
+	switch action {
+	case "allow":
+		return FilterAllow, nil
+	case "hold":
+		return FilterHold, nil
+	case "reject":
+		return FilterReject, nil
+	}
+	return FilterAllow, fmt.Errorf("unknown filter action %q", action)
+}
+
+// FilterResult is the outcome of checking a comment, with a reason when it
+// is not allowed.
+type FilterResult struct {
+	Action FilterAction
+	Reason string
+}
+
+var allow = FilterResult{Action: FilterAllow}
+
+// ContentFilter inspects a comment before it is written, deciding whether
+// it is stored, held for moderation or rejected.
+type ContentFilter interface {
+	Check(ctx context.Context, comment *domain.Comment) (FilterResult, error)
+}
+
+// FilterChain runs each filter in turn and returns the strictest result. It
+// stops at the first rejection.
+type FilterChain []ContentFilter
+
+func (f FilterChain) Check(ctx context.Context, comment *domain.Comment) (FilterResult, error) {
+	result := allow
+	for _, filter := range f {
+		r, err := filter.Check(ctx, comment)
+		if err != nil {
+			return FilterResult{}, err
+		}
+		if r.Action > result.Action {
+			result = r
+		}
+		if result.Action == FilterReject {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+type wordListFilter struct {
+	phrases []string
+	action  FilterAction
+}
+
+// NewWordListFilter matches comments containing any of the given words or
+// phrases. Matching ignores case and punctuation and only matches whole
+// words, so "ass" does not match "class".
+func NewWordListFilter(words []string, action FilterAction) ContentFilter {
+	var phrases []string
+	for _, word := range words {
+		if phrase := normalizeWords(word); phrase != "" {
+			phrases = append(phrases, " "+phrase+" ")
+		}
+	}
+
+	return &wordListFilter{phrases: phrases, action: action}
+}
+
+func (f *wordListFilter) Check(_ context.Context, comment *domain.Comment) (FilterResult, error) {
+	text := " " + normalizeWords(comment.Text) + " "
+	for _, phrase := range f.phrases {
+		if strings.Contains(text, phrase) {
+			return FilterResult{Action: f.action, Reason: "comment contains blocked words"}, nil
+		}
+	}
+
+	return allow, nil
+}
+
+// normalizeWords lowercases s and collapses everything that is not a letter
+// or digit into single spaces.
+func normalizeWords(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+`)
+
+type linkLimitFilter struct {
+	maxLinks int
+	action   FilterAction
+}
+
+// NewLinkLimitFilter matches comments containing more than maxLinks links.
+func NewLinkLimitFilter(maxLinks int, action FilterAction) ContentFilter {
+	return &linkLimitFilter{maxLinks: maxLinks, action: action}
+}
+
+func (f *linkLimitFilter) Check(_ context.Context, comment *domain.Comment) (FilterResult, error) {
+	if links := len(linkPattern.FindAllString(comment.Text, -1)); links > f.maxLinks {
+		return FilterResult{
+			Action: f.action,
+			Reason: fmt.Sprintf("comment contains %d links, at most %d allowed", links, f.maxLinks),
+		}, nil
+	}
+
+	return allow, nil
+}
+
+type duplicateFilter struct {
+	commentRepo domain.CommentRepository
+	window      time.Duration
+	action      FilterAction
+	now         func() time.Time
+}
+
+// NewDuplicateFilter matches comments whose text was already posted from the
+// same email address within window.
+func NewDuplicateFilter(commentRepo domain.CommentRepository, window time.Duration, action FilterAction) ContentFilter {
+	return &duplicateFilter{
+		commentRepo: commentRepo,
+		window:      window,
+		action:      action,
+		now:         time.Now,
+	}
+}
+
+func (f *duplicateFilter) Check(ctx context.Context, comment *domain.Comment) (FilterResult, error) {
+	count, err := f.commentRepo.CountDuplicates(ctx, comment, f.now().Add(-f.window))
+	if err != nil {
+		return FilterResult{}, fmt.Errorf("count duplicate comments: %w", err)
+	}
+
+	if count > 0 {
+		return FilterResult{Action: f.action, Reason: "duplicate comment"}, nil
+	}
+
+	return allow, nil
+}
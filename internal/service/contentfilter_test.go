@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yasv98/movies-api/internal/domain"
+)
+
+func TestWordListFilter(t *testing.T) {
+	filter := NewWordListFilter([]string{"spam", "Buy Now"}, FilterReject)
+
+	tests := map[string]struct {
+		text     string
+		expected FilterAction
+	}{
+		"Clean text":               {text: "A wonderful film.", expected: FilterAllow},
+		"Blocked word":             {text: "This is SPAM!", expected: FilterReject},
+		"Blocked phrase":           {text: "buy   now, cheap", expected: FilterReject},
+		"Blocked word inside word": {text: "Spammy but fine", expected: FilterAllow},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := filter.Check(context.Background(), &domain.Comment{Text: tt.text})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got.Action)
+		})
+	}
+}
+
+func TestLinkLimitFilter(t *testing.T) {
+	filter := NewLinkLimitFilter(1, FilterHold)
+
+	tests := map[string]struct {
+		text     string
+		expected FilterAction
+	}{
+		"No links":       {text: "No links here", expected: FilterAllow},
+		"Within limit":   {text: "See https://example.com", expected: FilterAllow},
+		"Over limit":     {text: "http://a.example and www.b.example", expected: FilterHold},
+		"Mixed case URL": {text: "HTTPS://A.EXAMPLE HTTP://B.EXAMPLE", expected: FilterHold},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := filter.Check(context.Background(), &domain.Comment{Text: tt.text})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got.Action)
+		})
+	}
+}
+
+type duplicateCounter struct {
+	domain.CommentRepository
+	count int64
+	since time.Time
+}
+
+func (d *duplicateCounter) CountDuplicates(_ context.Context, _ *domain.Comment, since time.Time) (int64, error) {
+	d.since = since
+	return d.count, nil
+}
+
+func TestDuplicateFilter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := &duplicateCounter{count: 1}
+	filter := NewDuplicateFilter(repo, 10*time.Minute, FilterReject).(*duplicateFilter)
+	filter.now = func() time.Time { return now }
+
+	got, err := filter.Check(context.Background(), &domain.Comment{Email: "a@example.com", Text: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, FilterReject, got.Action)
+	assert.Equal(t, now.Add(-10*time.Minute), repo.since)
+
+	repo.count = 0
+	got, err = filter.Check(context.Background(), &domain.Comment{Email: "a@example.com", Text: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, FilterAllow, got.Action)
+}
+
+type staticFilter FilterResult
+
+func (f staticFilter) Check(context.Context, *domain.Comment) (FilterResult, error) {
+	return FilterResult(f), nil
+}
+
+func TestFilterChain(t *testing.T) {
+	allowFilter := staticFilter{Action: FilterAllow}
+	holdFilter := staticFilter{Action: FilterHold, Reason: "hold"}
+	rejectFilter := staticFilter{Action: FilterReject, Reason: "reject"}
+
+	tests := map[string]struct {
+		chain    FilterChain
+		expected FilterResult
+	}{
+		"Empty chain allows":     {chain: nil, expected: FilterResult{Action: FilterAllow}},
+		"All allow":              {chain: FilterChain{allowFilter, allowFilter}, expected: FilterResult{Action: FilterAllow}},
+		"Hold wins over allow":   {chain: FilterChain{allowFilter, holdFilter}, expected: FilterResult(holdFilter)},
+		"Reject wins over hold":  {chain: FilterChain{holdFilter, rejectFilter}, expected: FilterResult(rejectFilter)},
+		"Reject stops the chain": {chain: FilterChain{rejectFilter, holdFilter}, expected: FilterResult(rejectFilter)},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := tt.chain.Check(context.Background(), &domain.Comment{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/yasv98/movies-api/internal/domain"
+)
+
+// DefaultIdempotencyTTL is how long responses to requests with an
+// idempotency key are kept when no TTL is configured.
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// MaxIdempotencyKeyLength bounds the keys clients may send.
+const MaxIdempotencyKeyLength = 255
+
+// idempotencyLockTimeout is how long a key stays reserved for a request
+// that has not completed, so a crash mid-request does not block retries
+// until the TTL.
+const idempotencyLockTimeout = time.Minute
+
+// idempotencyCompleteAttempts is how many times storing a response is
+// tried. A response that is never stored lets a retry repeat the request
+// once the reservation times out.
+const idempotencyCompleteAttempts = 3
+
+// idempotencyCompleteBackoff is the wait before the first retry of storing
+// a response, doubling with each further retry.
+const idempotencyCompleteBackoff = 50 * time.Millisecond
+
+// IdempotencyService lets requests carrying an idempotency key be retried
+// safely, answering retries with the response to the original request.
+type IdempotencyService struct {
+	idempotencyRepo domain.IdempotencyRepository
+	ttl             time.Duration
+	now             func() time.Time
+	backoff         time.Duration
+}
+
+func NewIdempotencyService(idempotencyRepo domain.IdempotencyRepository, ttl time.Duration) *IdempotencyService {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+
+	return &IdempotencyService{
+		idempotencyRepo: idempotencyRepo,
+		ttl:             ttl,
+		now:             time.Now,
+		backoff:         idempotencyCompleteBackoff,
+	}
+}
+
+// Begin reserves key within scope for request, which identifies the request
+// when marshalled to JSON. It returns nil if the request should go ahead, or
+// the original request once it has completed. Reusing a key for a different
+// request returns ErrIdempotencyKeyReused and retrying before the original
+// completes returns ErrIdempotencyKeyInProgress.
+func (s *IdempotencyService) Begin(ctx context.Context, scope, key string, request any) (*domain.IdempotentRequest, error) {
+	if key == "" || len(key) > MaxIdempotencyKeyLength {
+		return nil, domain.ErrInvalidIdempotencyKey
+	}
+
+	requestHash, err := hashRequest(request)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	reserved := &domain.IdempotentRequest{
+		ID:          idempotencyID(scope, key),
+		RequestHash: requestHash,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(idempotencyLockTimeout),
+	}
+
+	// The TTL monitor only runs periodically, so a record may outlive its
+	// expiry. One that has is replaced.
+	for attempt := 0; attempt < 2; attempt++ {
+		err := s.idempotencyRepo.Create(ctx, reserved)
+		if err != domain.ErrIdempotencyKeyExists {
+			return nil, err
+		}
+
+		existing, err := s.idempotencyRepo.Get(ctx, reserved.ID)
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil {
+			continue
+		}
+		if !existing.ExpiresAt.After(now) {
+			// Only the expired record is removed, not one another request
+			// reserved or completed in the meantime.
+			if err := s.idempotencyRepo.DeleteExpired(ctx, reserved.ID, now); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		switch {
+		case existing.RequestHash != requestHash:
+			return nil, domain.ErrIdempotencyKeyReused
+		case !existing.Completed():
+			return nil, domain.ErrIdempotencyKeyInProgress
+		default:
+			return existing, nil
+		}
+	}
+
+	return nil, domain.ErrIdempotencyKeyInProgress
+}
+
+// Complete stores the response to the request reserved by Begin so retries
+// are answered with it until the TTL passes. Failures are retried, as a
+// request whose response is lost would be repeated by the next retry.
+func (s *IdempotencyService) Complete(ctx context.Context, scope, key string, status int, contentType string, body []byte) error {
+	id := idempotencyID(scope, key)
+	backoff := s.backoff
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = s.idempotencyRepo.Complete(ctx, id, status, contentType, body, s.now().Add(s.ttl))
+		if err == nil || attempt == idempotencyCompleteAttempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// Release frees a key reserved by Begin without storing a response, so a
+// request that failed can be retried.
+func (s *IdempotencyService) Release(ctx context.Context, scope, key string) error {
+	return s.idempotencyRepo.Delete(ctx, idempotencyID(scope, key))
+}
+
+// idempotencyID combines the scope and key, hashed so clients cannot pick
+// the stored ID.
+func idempotencyID(scope, key string) string {
+	sum := sha256.Sum256([]byte(scope + "\x00" + key))
+	return hex.EncodeToString(sum[:])
+}
+
+func hashRequest(request any) (string, error) {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
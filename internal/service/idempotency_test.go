@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yasv98/movies-api/internal/domain"
+)
+
+type idempotencyStore struct {
+	requests map[string]domain.IdempotentRequest
+	// completeErrs fails that many calls to Complete before succeeding.
+	completeErrs int
+}
+
+func (s *idempotencyStore) Create(_ context.Context, request *domain.IdempotentRequest) error {
+	if _, ok := s.requests[request.ID]; ok {
+		return domain.ErrIdempotencyKeyExists
+	}
+	s.requests[request.ID] = *request
+	return nil
+}
+
+func (s *idempotencyStore) Get(_ context.Context, id string) (*domain.IdempotentRequest, error) {
+	request, ok := s.requests[id]
+	if !ok {
+		return nil, nil
+	}
+	return &request, nil
+}
+
+func (s *idempotencyStore) Complete(_ context.Context, id string, status int, contentType string, body []byte, expiresAt time.Time) error {
+	if s.completeErrs > 0 {
+		s.completeErrs--
+		return errors.New("write failed")
+	}
+	request := s.requests[id]
+	request.Status = status
+	request.ContentType = contentType
+	request.Body = body
+	request.ExpiresAt = expiresAt
+	s.requests[id] = request
+	return nil
+}
+
+func (s *idempotencyStore) Delete(_ context.Context, id string) error {
+	delete(s.requests, id)
+	return nil
+}
+
+func (s *idempotencyStore) DeleteExpired(_ context.Context, id string, now time.Time) error {
+	if request, ok := s.requests[id]; ok && !request.ExpiresAt.After(now) {
+		delete(s.requests, id)
+	}
+	return nil
+}
+
+func TestIdempotencyService(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	s := NewIdempotencyService(&idempotencyStore{requests: map[string]domain.IdempotentRequest{}}, time.Hour)
+	s.now = func() time.Time { return now }
+
+	request := map[string]string{"text": "Great movie!"}
+
+	original, err := s.Begin(ctx, "scope", "key", request)
+	require.NoError(t, err)
+	assert.Nil(t, original, "first use goes ahead")
+
+	_, err = s.Begin(ctx, "scope", "key", request)
+	assert.Equal(t, domain.ErrIdempotencyKeyInProgress, err)
+
+	_, err = s.Begin(ctx, "scope", "key", map[string]string{"text": "Changed"})
+	assert.Equal(t, domain.ErrIdempotencyKeyReused, err)
+
+	require.NoError(t, s.Complete(ctx, "scope", "key", 201, "application/json", []byte(`{"id":"1"}`)))
+
+	original, err = s.Begin(ctx, "scope", "key", request)
+	require.NoError(t, err)
+	require.NotNil(t, original)
+	assert.Equal(t, 201, original.Status)
+	assert.Equal(t, []byte(`{"id":"1"}`), original.Body)
+
+	// Keys are independent across scopes.
+	original, err = s.Begin(ctx, "other", "key", request)
+	require.NoError(t, err)
+	assert.Nil(t, original)
+
+	// Expired records are replaced even before they are removed.
+	now = now.Add(2 * time.Hour)
+	original, err = s.Begin(ctx, "scope", "key", map[string]string{"text": "Changed"})
+	require.NoError(t, err)
+	assert.Nil(t, original)
+
+	// Released keys can be used again straight away.
+	require.NoError(t, s.Release(ctx, "scope", "key"))
+	original, err = s.Begin(ctx, "scope", "key", request)
+	require.NoError(t, err)
+	assert.Nil(t, original)
+}
+
+func TestIdempotencyService_InvalidKey(t *testing.T) {
+	s := NewIdempotencyService(&idempotencyStore{requests: map[string]domain.IdempotentRequest{}}, 0)
+
+	_, err := s.Begin(context.Background(), "scope", string(make([]byte, MaxIdempotencyKeyLength+1)), nil)
+	assert.Equal(t, domain.ErrInvalidIdempotencyKey, err)
+}
+
+func TestIdempotencyService_CompleteRetries(t *testing.T) {
+	ctx := context.Background()
+	store := &idempotencyStore{requests: map[string]domain.IdempotentRequest{}}
+	s := NewIdempotencyService(store, time.Hour)
+	s.backoff = 0
+
+	request := map[string]string{"text": "Great movie!"}
+	_, err := s.Begin(ctx, "scope", "key", request)
+	require.NoError(t, err)
+
+	store.completeErrs = idempotencyCompleteAttempts - 1
+	require.NoError(t, s.Complete(ctx, "scope", "key", 201, "application/json", []byte(`{"id":"1"}`)))
+
+	original, err := s.Begin(ctx, "scope", "key", request)
+	require.NoError(t, err)
+	require.NotNil(t, original)
+	assert.Equal(t, 201, original.Status)
+
+	_, err = s.Begin(ctx, "scope", "other", request)
+	require.NoError(t, err)
+
+	store.completeErrs = idempotencyCompleteAttempts
+	assert.Error(t, s.Complete(ctx, "scope", "other", 201, "application/json", []byte(`{"id":"2"}`)))
+}
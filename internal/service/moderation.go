@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/yasv98/movies-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DefaultFlagThreshold is the number of flags that hides a comment when no
+// threshold is configured.
+const DefaultFlagThreshold = 3
+
+const maxFlagReasonLength = 500
+
+type ModerationService struct {
+	commentRepo    domain.CommentRepository
+	flagRepo       domain.FlagRepository
+	moderationRepo domain.ModerationRepository
+
+	mu            sync.RWMutex
+	flagThreshold int
+	moderators    map[string]bool
+}
+
+func NewModerationService(
+	commentRepo domain.CommentRepository,
+	flagRepo domain.FlagRepository,
+	moderationRepo domain.ModerationRepository,
+	flagThreshold int,
+	moderators []string,
+) *ModerationService {
+	m := &ModerationService{
+		commentRepo:    commentRepo,
+		flagRepo:       flagRepo,
+		moderationRepo: moderationRepo,
+	}
+	m.SetPolicy(flagThreshold, moderators)
+
+	return m
+}
+
+// SetPolicy replaces the flag threshold and the moderators, taking effect
+// for subsequent requests.
+func (m *ModerationService) SetPolicy(flagThreshold int, moderators []string) {
+	if flagThreshold <= 0 {
+		flagThreshold = DefaultFlagThreshold
+	}
+
+	moderatorSet := make(map[string]bool, len(moderators))
+	for _, moderator := range moderators {
+		moderatorSet[moderator] = true
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.flagThreshold = flagThreshold
+	m.moderators = moderatorSet
+}
+
+func (m *ModerationService) isModerator(userID string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.moderators[userID]
+}
+
+// FlagComment records the user's flag against a comment. Each user can flag
+// a comment once.
+func (m *ModerationService) FlagComment(ctx context.Context, movieID, commentID primitive.ObjectID, userID, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || len(reason) > maxFlagReasonLength {
+		return domain.ErrInvalidFlagReason
+	}
+
+	if _, err := m.commentRepo.GetMovieComment(ctx, movieID, commentID); err != nil {
+		return err
+	}
+
+	if err := m.flagRepo.Create(ctx, &domain.Flag{
+		CommentID: commentID,
+		MovieID:   movieID,
+		UserID:    userID,
+		Reason:    reason,
+	}); err != nil {
+		return err
+	}
+
+	m.mu.RLock()
+	flagThreshold := m.flagThreshold
+	m.mu.RUnlock()
+
+	return m.moderationRepo.AddFlag(ctx, movieID, commentID, flagThreshold)
+}
+
+func (m *ModerationService) GetQueue(ctx context.Context, userID string, page, limit int) ([]domain.Comment, error) {
+	if !m.isModerator(userID) {
+		return nil, domain.ErrNotModerator
+	}
+
+	return m.moderationRepo.GetQueue(ctx, page, limit)
+}
+
+func (m *ModerationService) ApproveComment(ctx context.Context, commentID primitive.ObjectID, userID, note string) (*domain.Comment, error) {
+	return m.decide(ctx, commentID, userID, domain.CommentStatusApproved, note)
+}
+
+func (m *ModerationService) RejectComment(ctx context.Context, commentID primitive.ObjectID, userID, note string) (*domain.Comment, error) {
+	return m.decide(ctx, commentID, userID, domain.CommentStatusRejected, note)
+}
+
+func (m *ModerationService) decide(ctx context.Context, commentID primitive.ObjectID, userID string, status domain.CommentStatus, note string) (*domain.Comment, error) {
+	if !m.isModerator(userID) {
+		return nil, domain.ErrNotModerator
+	}
+
+	return m.moderationRepo.Decide(ctx, commentID, &domain.ModerationDecision{
+		Moderator: userID,
+		Status:    status,
+		Note:      strings.TrimSpace(note),
+	})
+}
@@ -2,25 +2,156 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/yasv98/movies-api/internal/domain"
+	"github.com/yasv98/movies-api/internal/textnorm"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+const (
+	// MaxSimilarMovies caps the number of recommendations returned for a
+	// movie.
+	MaxSimilarMovies = 50
+	// MaxSuggestions caps the number of autocomplete suggestions.
+	MaxSuggestions = 20
+	// autocompleteTimeout bounds how long a suggestion lookup may take, as a
+	// slow answer is no use to someone typing.
+	autocompleteTimeout = 500 * time.Millisecond
+)
+
 type MovieService struct {
-	movieRepo domain.MovieRepository
+	movieRepo   domain.MovieRepository
+	recommender Recommender
 }
 
-func NewMovieService(movieRepo domain.MovieRepository) *MovieService {
+func NewMovieService(movieRepo domain.MovieRepository, recommender Recommender) *MovieService {
 	return &MovieService{
-		movieRepo: movieRepo,
+		movieRepo:   movieRepo,
+		recommender: recommender,
+	}
+}
+
+// GetMovie returns a movie, loading only the given BSON fields if any are
+// given.
+func (u *MovieService) GetMovie(ctx context.Context, id primitive.ObjectID, fields []string) (*domain.Movie, error) {
+	ctx, span := tracer.Start(ctx, "MovieService.GetMovie")
+	defer span.End()
+
+	return u.movieRepo.GetMovie(ctx, id, fields)
+}
+
+func (u *MovieService) GetMovies(ctx context.Context, query domain.MovieQuery) ([]domain.Movie, error) {
+	ctx, span := tracer.Start(ctx, "MovieService.GetMovies")
+	defer span.End()
+
+	if query.Sort != domain.MovieSortDefault && query.Sort != domain.MovieSortCommunityRating {
+		return nil, domain.ErrInvalidMovieSort
+	}
+
+	return u.movieRepo.GetMovies(ctx, query)
+}
+
+// GetMoviesByIDs fetches several movies at once, returning them in the
+// order requested along with the IDs that were not found. Repeated IDs are
+// only returned once.
+func (u *MovieService) GetMoviesByIDs(ctx context.Context, ids []primitive.ObjectID, fields []string) (*domain.MovieBatch, error) {
+	ctx, span := tracer.Start(ctx, "MovieService.GetMoviesByIDs")
+	defer span.End()
+
+	if len(ids) == 0 || len(ids) > domain.MaxMovieBatchSize {
+		return nil, domain.ErrInvalidBatchSize
+	}
+
+	seen := make(map[primitive.ObjectID]bool, len(ids))
+	unique := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	movies, err := u.movieRepo.GetMoviesByIDs(ctx, unique, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[primitive.ObjectID]domain.Movie, len(movies))
+	for _, movie := range movies {
+		byID[movie.ID] = movie
 	}
+
+	batch := &domain.MovieBatch{
+		Movies:   make([]domain.Movie, 0, len(unique)),
+		NotFound: []primitive.ObjectID{},
+	}
+	for _, id := range unique {
+		movie, ok := byID[id]
+		if !ok {
+			batch.NotFound = append(batch.NotFound, id)
+			continue
+		}
+		batch.Movies = append(batch.Movies, movie)
+	}
+
+	return batch, nil
+}
+
+// GetMovieFacets counts the movies matching query by each facet. Facets
+// requested more than once are only counted once.
+func (u *MovieService) GetMovieFacets(ctx context.Context, query domain.MovieQuery, facets []domain.MovieFacet) (domain.MovieFacets, error) {
+	ctx, span := tracer.Start(ctx, "MovieService.GetMovieFacets")
+	defer span.End()
+
+	seen := make(map[domain.MovieFacet]bool, len(facets))
+	unique := make([]domain.MovieFacet, 0, len(facets))
+	for _, facet := range facets {
+		if !facet.Valid() {
+			return nil, domain.ErrInvalidFacet
+		}
+		if !seen[facet] {
+			seen[facet] = true
+			unique = append(unique, facet)
+		}
+	}
+
+	return u.movieRepo.GetMovieFacets(ctx, query, unique)
 }
 
-func (u *MovieService) GetMovie(ctx context.Context, id primitive.ObjectID) (*domain.Movie, error) {
-	return u.movieRepo.GetMovie(ctx, id)
+func (u *MovieService) GetSimilarMovies(ctx context.Context, id primitive.ObjectID, limit int) ([]domain.SimilarMovie, error) {
+	ctx, span := tracer.Start(ctx, "MovieService.GetSimilarMovies")
+	defer span.End()
+
+	if limit <= 0 || limit > MaxSimilarMovies {
+		return nil, domain.ErrInvalidLimit
+	}
+
+	movie, err := u.movieRepo.GetMovie(ctx, id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.recommender.Similar(ctx, movie, limit)
 }
 
-func (u *MovieService) GetMovies(ctx context.Context, titleFilter string, page, limit int) ([]domain.Movie, error) {
-	return u.movieRepo.GetMovies(ctx, titleFilter, page, limit)
+// Autocomplete suggests movies whose title starts with prefix, ignoring
+// case and accents.
+func (u *MovieService) Autocomplete(ctx context.Context, prefix string, limit int) ([]domain.MovieSuggestion, error) {
+	ctx, span := tracer.Start(ctx, "MovieService.Autocomplete")
+	defer span.End()
+
+	if limit <= 0 || limit > MaxSuggestions {
+		return nil, domain.ErrInvalidLimit
+	}
+
+	prefix = textnorm.Fold(prefix)
+	if prefix == "" {
+		return nil, domain.ErrInvalidPrefix
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, autocompleteTimeout)
+	defer cancel()
+
+	return u.movieRepo.Autocomplete(ctx, prefix, limit)
 }
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+
+	"github.com/yasv98/movies-api/internal/domain"
+	"github.com/yasv98/movies-api/internal/textnorm"
+)
+
+const (
+	// MaxPeopleResults caps the number of people returned by a search.
+	MaxPeopleResults = 50
+	// MaxPersonMovies caps the page size of a person's movies.
+	MaxPersonMovies = 100
+)
+
+type PersonService struct {
+	personRepo domain.PersonRepository
+}
+
+func NewPersonService(personRepo domain.PersonRepository) *PersonService {
+	return &PersonService{
+		personRepo: personRepo,
+	}
+}
+
+// SearchPeople finds directors and cast members by name, ignoring case and
+// accents.
+func (s *PersonService) SearchPeople(ctx context.Context, name string, limit int) ([]domain.Person, error) {
+	if limit <= 0 || limit > MaxPeopleResults {
+		return nil, domain.ErrInvalidLimit
+	}
+
+	name = textnorm.Fold(name)
+	if name == "" {
+		return nil, domain.ErrInvalidPersonName
+	}
+
+	return s.personRepo.SearchPeople(ctx, name, limit)
+}
+
+// GetFilmography returns a person's stats and a page of their movies. The
+// name is matched ignoring case and accents.
+func (s *PersonService) GetFilmography(ctx context.Context, name string, sort domain.PersonMovieSort, page, limit int) (*domain.Filmography, error) {
+	if sort == "" {
+		sort = domain.PersonMovieSortYear
+	}
+	if sort != domain.PersonMovieSortYear && sort != domain.PersonMovieSortRating {
+		return nil, domain.ErrInvalidPersonSort
+	}
+	if limit > MaxPersonMovies {
+		return nil, domain.ErrInvalidLimit
+	}
+
+	name = textnorm.Fold(name)
+	if name == "" {
+		return nil, domain.ErrInvalidPersonName
+	}
+
+	person, err := s.personRepo.GetPerson(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	movies, err := s.personRepo.GetPersonMovies(ctx, name, sort, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	if movies == nil {
+		movies = []domain.PersonMovie{}
+	}
+
+	return &domain.Filmography{
+		Person: *person,
+		Movies: movies,
+	}, nil
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+
+	"github.com/yasv98/movies-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type RatingService struct {
+	ratingRepo domain.RatingRepository
+	movieRepo  domain.MovieRepository
+}
+
+func NewRatingService(ratingRepo domain.RatingRepository, movieRepo domain.MovieRepository) *RatingService {
+	return &RatingService{
+		ratingRepo: ratingRepo,
+		movieRepo:  movieRepo,
+	}
+}
+
+// SetRating creates or replaces the user's rating for a movie and refreshes
+// the movie's community rating.
+func (r *RatingService) SetRating(ctx context.Context, movieID primitive.ObjectID, userID string, score int) (*domain.Rating, error) {
+	if score < domain.MinRatingScore || score > domain.MaxRatingScore {
+		return nil, domain.ErrInvalidRatingScore
+	}
+
+	if _, err := r.movieRepo.GetMovie(ctx, movieID, nil); err != nil {
+		return nil, err
+	}
+
+	rating := &domain.Rating{
+		MovieID: movieID,
+		UserID:  userID,
+		Score:   score,
+	}
+	if err := r.ratingRepo.Set(ctx, rating); err != nil {
+		return nil, err
+	}
+
+	if err := r.ratingRepo.RefreshCommunityRating(ctx, movieID); err != nil {
+		return nil, err
+	}
+
+	return rating, nil
+}
+
+func (r *RatingService) GetRating(ctx context.Context, movieID primitive.ObjectID, userID string) (*domain.Rating, error) {
+	return r.ratingRepo.Get(ctx, movieID, userID)
+}
+
+// DeleteRating removes the user's rating for a movie and refreshes the
+// movie's community rating.
+func (r *RatingService) DeleteRating(ctx context.Context, movieID primitive.ObjectID, userID string) error {
+	if err := r.ratingRepo.Delete(ctx, movieID, userID); err != nil {
+		return err
+	}
+
+	return r.ratingRepo.RefreshCommunityRating(ctx, movieID)
+}
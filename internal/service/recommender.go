@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/yasv98/movies-api/internal/domain"
+)
+
+// Recommender finds movies similar to a given movie, most similar first.
+type Recommender interface {
+	Similar(ctx context.Context, movie *domain.Movie, limit int) ([]domain.SimilarMovie, error)
+}
+
+// candidatePoolFactor controls how many candidates are fetched for each
+// recommendation returned, leaving room for the final ranking to reorder
+// the repository's rough overlap ordering.
+const candidatePoolFactor = 10
+
+type aggregationRecommender struct {
+	movieRepo domain.MovieRepository
+}
+
+// NewAggregationRecommender returns a Recommender that fetches candidates
+// sharing genres, directors or cast through a repository aggregation and
+// ranks them with similarityScore.
+func NewAggregationRecommender(movieRepo domain.MovieRepository) Recommender {
+	return &aggregationRecommender{movieRepo: movieRepo}
+}
+
+func (a *aggregationRecommender) Similar(ctx context.Context, movie *domain.Movie, limit int) ([]domain.SimilarMovie, error) {
+	candidates, err := a.movieRepo.GetSimilarCandidates(ctx, movie, limit*candidatePoolFactor)
+	if err != nil {
+		return nil, err
+	}
+
+	return rankSimilar(movie, candidates, limit), nil
+}
+
+// Weights for each feature a candidate shares with the target movie.
+const (
+	genreWeight    = 1.0
+	directorWeight = 3.0
+	castWeight     = 1.5
+	// maxSharedCast stops large ensemble casts from dominating the score.
+	maxSharedCast = 3
+	eraWeight     = 2.0
+	// eraSpan is the number of years apart at which two movies no longer
+	// count as the same era.
+	eraSpan = 20.0
+)
+
+// similarityScore scores how alike candidate is to target. Shared genres,
+// directors and cast and release years close together add to the score,
+// which is then scaled by the candidate's IMDB rating so that, of two
+// equally similar movies, the better rated one ranks higher. Movies with
+// nothing in common score zero.
+func similarityScore(target, candidate *domain.Movie) float64 {
+	score := genreWeight * float64(sharedCount(target.Genres, candidate.Genres))
+	score += directorWeight * float64(sharedCount(target.Directors, candidate.Directors))
+	score += castWeight * float64(min(sharedCount(target.Cast, candidate.Cast), maxSharedCast))
+
+	if score == 0 {
+		return 0
+	}
+
+	if target.Year > 0 && candidate.Year > 0 {
+		gap := math.Abs(float64(target.Year - candidate.Year))
+		score += eraWeight * math.Max(0, 1-gap/eraSpan)
+	}
+
+	// Ratings range from 0 to 10, scaling the score by 0.5 to 1.
+	rating := math.Min(math.Max(candidate.IMDB.Rating, 0), 10)
+	return score * (0.5 + rating/20)
+}
+
+// rankSimilar scores the candidates against target and returns the top
+// limit, dropping candidates with nothing in common. Ties are broken by
+// IMDB rating and then ID so the ranking is deterministic.
+func rankSimilar(target *domain.Movie, candidates []domain.Movie, limit int) []domain.SimilarMovie {
+	ranked := make([]domain.SimilarMovie, 0, len(candidates))
+	for i := range candidates {
+		candidate := &candidates[i]
+		if candidate.ID == target.ID {
+			continue
+		}
+
+		score := similarityScore(target, candidate)
+		if score <= 0 {
+			continue
+		}
+
+		ranked = append(ranked, domain.SimilarMovie{
+			MovieSummary: candidate.Summary(),
+			Score:        math.Round(score*1000) / 1000,
+		})
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		if ranked[i].IMDB.Rating != ranked[j].IMDB.Rating {
+			return ranked[i].IMDB.Rating > ranked[j].IMDB.Rating
+		}
+		return ranked[i].ID.Hex() < ranked[j].ID.Hex()
+	})
+
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+
+	return ranked
+}
+
+// sharedCount returns the number of distinct values in both a and b.
+func sharedCount(a, b []string) int {
+	set := make(map[string]bool, len(a))
+	for _, v := range a {
+		set[v] = true
+	}
+
+	count := 0
+	for _, v := range b {
+		if set[v] {
+			count++
+			delete(set, v)
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yasv98/movies-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSimilarityScore(t *testing.T) {
+	target := &domain.Movie{
+		Genres:    []string{"Drama", "Crime"},
+		Directors: []string{"Francis Ford Coppola"},
+		Cast:      []string{"Al Pacino", "Marlon Brando", "James Caan", "Diane Keaton"},
+		Year:      1972,
+	}
+
+	tests := map[string]struct {
+		candidate *domain.Movie
+		expected  float64
+	}{
+		"Nothing in common": {
+			candidate: &domain.Movie{Genres: []string{"Comedy"}, Year: 1972, IMDB: domain.IMDB{Rating: 10}},
+			expected:  0,
+		},
+		"Shared genre in the same year with a perfect rating": {
+			candidate: &domain.Movie{Genres: []string{"Drama"}, Year: 1972, IMDB: domain.IMDB{Rating: 10}},
+			expected:  (genreWeight + eraWeight) * 1,
+		},
+		"Shared genre ten years apart with no rating": {
+			candidate: &domain.Movie{Genres: []string{"Drama"}, Year: 1982},
+			expected:  (genreWeight + eraWeight*0.5) * 0.5,
+		},
+		"Shared director outside the era": {
+			candidate: &domain.Movie{Directors: []string{"Francis Ford Coppola"}, Year: 2001, IMDB: domain.IMDB{Rating: 10}},
+			expected:  directorWeight,
+		},
+		"Shared cast is capped": {
+			candidate: &domain.Movie{Cast: []string{"Al Pacino", "Marlon Brando", "James Caan", "Diane Keaton"}, IMDB: domain.IMDB{Rating: 10}},
+			expected:  castWeight * maxSharedCast,
+		},
+		"Duplicate values count once": {
+			candidate: &domain.Movie{Genres: []string{"Drama", "Drama"}, IMDB: domain.IMDB{Rating: 10}},
+			expected:  genreWeight,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.InDelta(t, tt.expected, similarityScore(target, tt.candidate), 1e-9)
+		})
+	}
+}
+
+func TestRankSimilar(t *testing.T) {
+	ids := make([]primitive.ObjectID, 5)
+	for i := range ids {
+		ids[i] = primitive.NewObjectID()
+	}
+
+	target := domain.Movie{ID: ids[0], Genres: []string{"Drama"}, Directors: []string{"Sidney Lumet"}}
+	candidates := []domain.Movie{
+		// The target itself is never recommended.
+		target,
+		{ID: ids[1], Genres: []string{"Drama"}, IMDB: domain.IMDB{Rating: 6}},
+		{ID: ids[2], Directors: []string{"Sidney Lumet"}, IMDB: domain.IMDB{Rating: 6}},
+		{ID: ids[3], Genres: []string{"Drama"}, IMDB: domain.IMDB{Rating: 8}},
+		{ID: ids[4], Genres: []string{"Western"}, IMDB: domain.IMDB{Rating: 9}},
+	}
+
+	ranked := rankSimilar(&target, candidates, 10)
+
+	var got []primitive.ObjectID
+	for _, movie := range ranked {
+		got = append(got, movie.ID)
+	}
+	assert.Equal(t, []primitive.ObjectID{ids[2], ids[3], ids[1]}, got)
+
+	assert.Len(t, rankSimilar(&target, candidates, 2), 2)
+}
+
+func TestRankSimilar_TiesAreDeterministic(t *testing.T) {
+	a := primitive.NewObjectID()
+	b := primitive.NewObjectID()
+
+	target := domain.Movie{ID: primitive.NewObjectID(), Genres: []string{"Drama"}}
+	first := rankSimilar(&target, []domain.Movie{
+		{ID: b, Genres: []string{"Drama"}},
+		{ID: a, Genres: []string{"Drama"}},
+	}, 2)
+	second := rankSimilar(&target, []domain.Movie{
+		{ID: a, Genres: []string{"Drama"}},
+		{ID: b, Genres: []string{"Drama"}},
+	}, 2)
+
+	assert.Equal(t, first, second)
+	assert.Equal(t, a, first[0].ID)
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/yasv98/movies-api/internal/domain"
+	"github.com/yasv98/movies-api/internal/logging"
+)
+
+// DefaultReferenceRefreshInterval is how often reference values are
+// reloaded when no interval is configured.
+const DefaultReferenceRefreshInterval = 10 * time.Minute
+
+// ReferenceService serves the distinct values of movie fields from a cache,
+// as they change rarely and are expensive to count.
+type ReferenceService struct {
+	referenceRepo domain.ReferenceRepository
+
+	mu     sync.RWMutex
+	values map[domain.ReferenceKind][]domain.ReferenceValue
+}
+
+func NewReferenceService(referenceRepo domain.ReferenceRepository) *ReferenceService {
+	return &ReferenceService{
+		referenceRepo: referenceRepo,
+		values:        make(map[domain.ReferenceKind][]domain.ReferenceValue),
+	}
+}
+
+// GetValues returns the values of kind, loading them if they are not yet
+// cached.
+func (s *ReferenceService) GetValues(ctx context.Context, kind domain.ReferenceKind) ([]domain.ReferenceValue, error) {
+	if !kind.Valid() {
+		return nil, domain.ErrInvalidReferenceKind
+	}
+
+	s.mu.RLock()
+	values, ok := s.values[kind]
+	s.mu.RUnlock()
+	if ok {
+		return values, nil
+	}
+
+	return s.load(ctx, kind)
+}
+
+// Refresh reloads the values of every kind. Kinds that fail to load keep
+// their previously cached values.
+func (s *ReferenceService) Refresh(ctx context.Context) error {
+	var firstErr error
+	for _, kind := range domain.ReferenceKinds {
+		if _, err := s.load(ctx, kind); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// Run refreshes the cache every interval until ctx is done.
+func (s *ReferenceService) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultReferenceRefreshInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Refresh(ctx); err != nil {
+				logging.FromContext(ctx).Error("error refreshing reference values", "error", err)
+			}
+		}
+	}
+}
+
+func (s *ReferenceService) load(ctx context.Context, kind domain.ReferenceKind) ([]domain.ReferenceValue, error) {
+	values, err := s.referenceRepo.GetReferenceValues(ctx, kind)
+	if err != nil {
+		return nil, err
+	}
+	if values == nil {
+		values = []domain.ReferenceValue{}
+	}
+
+	s.mu.Lock()
+	s.values[kind] = values
+	s.mu.Unlock()
+
+	return values, nil
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yasv98/movies-api/internal/domain"
+)
+
+type referenceLoader struct {
+	values map[domain.ReferenceKind][]domain.ReferenceValue
+	err    error
+	loads  int
+}
+
+func (r *referenceLoader) GetReferenceValues(_ context.Context, kind domain.ReferenceKind) ([]domain.ReferenceValue, error) {
+	r.loads++
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.values[kind], nil
+}
+
+func TestReferenceService(t *testing.T) {
+	ctx := context.Background()
+	genres := []domain.ReferenceValue{{Value: "Drama", Count: 2}, {Value: "Comedy", Count: 1}}
+	repo := &referenceLoader{values: map[domain.ReferenceKind][]domain.ReferenceValue{
+		domain.ReferenceGenres: genres,
+	}}
+	s := NewReferenceService(repo)
+
+	got, err := s.GetValues(ctx, domain.ReferenceGenres)
+	require.NoError(t, err)
+	assert.Equal(t, genres, got)
+
+	got, err = s.GetValues(ctx, domain.ReferenceGenres)
+	require.NoError(t, err)
+	assert.Equal(t, genres, got)
+	assert.Equal(t, 1, repo.loads, "cached values are not reloaded")
+
+	got, err = s.GetValues(ctx, domain.ReferenceLanguages)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.ReferenceValue{}, got)
+
+	_, err = s.GetValues(ctx, "budgets")
+	assert.Equal(t, domain.ErrInvalidReferenceKind, err)
+
+	updated := []domain.ReferenceValue{{Value: "Drama", Count: 3}}
+	repo.values[domain.ReferenceGenres] = updated
+	require.NoError(t, s.Refresh(ctx))
+
+	got, err = s.GetValues(ctx, domain.ReferenceGenres)
+	require.NoError(t, err)
+	assert.Equal(t, updated, got)
+
+	repo.err = errors.New("unavailable")
+	assert.Equal(t, repo.err, s.Refresh(ctx))
+
+	got, err = s.GetValues(ctx, domain.ReferenceGenres)
+	require.NoError(t, err)
+	assert.Equal(t, updated, got, "failed refreshes keep the cached values")
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+
+	"github.com/yasv98/movies-api/internal/domain"
+)
+
+// MaxTopDirectors caps the number of directors ranked by TopDirectors.
+const MaxTopDirectors = 100
+
+type StatsService struct {
+	statsRepo domain.StatsRepository
+}
+
+func NewStatsService(statsRepo domain.StatsRepository) *StatsService {
+	return &StatsService{
+		statsRepo: statsRepo,
+	}
+}
+
+func (s *StatsService) MoviesPerYear(ctx context.Context) ([]domain.YearCount, error) {
+	return s.statsRepo.MoviesPerYear(ctx)
+}
+
+func (s *StatsService) GenreDistribution(ctx context.Context) ([]domain.GenreCount, error) {
+	return s.statsRepo.GenreDistribution(ctx)
+}
+
+func (s *StatsService) RatingsByGenreDecade(ctx context.Context) ([]domain.GenreDecadeRating, error) {
+	return s.statsRepo.RatingsByGenreDecade(ctx)
+}
+
+func (s *StatsService) TopDirectors(ctx context.Context, query domain.TopDirectorsQuery) ([]domain.DirectorRating, error) {
+	if query.Limit <= 0 || query.Limit > MaxTopDirectors {
+		return nil, domain.ErrInvalidLimit
+	}
+	if query.MinVotes < 0 {
+		return nil, domain.ErrInvalidMinVotes
+	}
+
+	return s.statsRepo.TopDirectors(ctx, query)
+}
+
+func (s *StatsService) CommentActivity(ctx context.Context, query domain.CommentActivityQuery) ([]domain.CommentActivity, error) {
+	if !query.Interval.Valid() {
+		return nil, domain.ErrInvalidStatsInterval
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && !query.From.Before(query.To) {
+		return nil, domain.ErrInvalidStatsRange
+	}
+
+	return s.statsRepo.CommentActivity(ctx, query)
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+
+	"github.com/yasv98/movies-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// movieSummariesByID fetches summaries for the given movies in one query,
+// keyed by movie ID. Movies that do not exist are missing from the map.
+func movieSummariesByID(ctx context.Context, movieRepo domain.MovieRepository, ids []primitive.ObjectID) (map[primitive.ObjectID]*domain.MovieSummary, error) {
+	movies, err := movieRepo.GetMovieSummaries(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[primitive.ObjectID]*domain.MovieSummary, len(movies))
+	for i := range movies {
+		byID[movies[i].ID] = &movies[i]
+	}
+
+	return byID, nil
+}
@@ -0,0 +1,7 @@
+package service
+
+import "go.opentelemetry.io/otel"
+
+// tracer records a span for each service method, so slow requests can be
+// attributed to the service or the queries it makes.
+var tracer = otel.Tracer("github.com/yasv98/movies-api/internal/service")
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+
+	"github.com/yasv98/movies-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type UserListService struct {
+	listRepo  domain.UserListRepository
+	movieRepo domain.MovieRepository
+}
+
+func NewUserListService(listRepo domain.UserListRepository, movieRepo domain.MovieRepository) *UserListService {
+	return &UserListService{
+		listRepo:  listRepo,
+		movieRepo: movieRepo,
+	}
+}
+
+func (u *UserListService) AddMovie(ctx context.Context, userID string, list domain.UserListKind, movieID primitive.ObjectID) (*domain.UserListEntry, error) {
+	if _, err := u.movieRepo.GetMovie(ctx, movieID, nil); err != nil {
+		return nil, err
+	}
+
+	entry := &domain.UserListEntry{
+		UserID:  userID,
+		List:    list,
+		MovieID: movieID,
+	}
+	if err := u.listRepo.Add(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (u *UserListService) RemoveMovie(ctx context.Context, userID string, list domain.UserListKind, movieID primitive.ObjectID) error {
+	return u.listRepo.Remove(ctx, userID, list, movieID)
+}
+
+// GetList returns a page of the user's list with each entry's movie
+// summary, fetched in one query.
+func (u *UserListService) GetList(ctx context.Context, userID string, list domain.UserListKind, page, limit int) ([]domain.UserListItem, error) {
+	entries, err := u.listRepo.List(ctx, userID, list, page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.MovieID)
+	}
+
+	byID, err := movieSummariesByID(ctx, u.movieRepo, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]domain.UserListItem, 0, len(entries))
+	for _, entry := range entries {
+		items = append(items, domain.UserListItem{
+			UserListEntry: entry,
+			Movie:         byID[entry.MovieID],
+		})
+	}
+
+	return items, nil
+}
+
+func (u *UserListService) Reorder(ctx context.Context, userID string, list domain.UserListKind, movieIDs []primitive.ObjectID) error {
+	return u.listRepo.Reorder(ctx, userID, list, movieIDs)
+}
@@ -0,0 +1,24 @@
+// Package textnorm normalizes text for case and accent insensitive
+// matching.
+package textnorm
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Fold lowercases s, strips diacritics and collapses runs of whitespace, so
+// that "  Amélie " and "amelie" fold to the same string.
+func Fold(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, s)
+	if err != nil {
+		folded = s
+	}
+
+	return strings.Join(strings.Fields(strings.ToLower(folded)), " ")
+}
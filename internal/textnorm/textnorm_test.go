@@ -0,0 +1,27 @@
+package textnorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFold(t *testing.T) {
+	tests := map[string]struct {
+		input    string
+		expected string
+	}{
+		"Plain text":          {input: "The Matrix", expected: "the matrix"},
+		"Diacritics":          {input: "Amélie", expected: "amelie"},
+		"Mixed diacritics":    {input: "Ça Ira, Señor Ñoño", expected: "ca ira, senor nono"},
+		"Extra whitespace":    {input: "  Blade \t Runner  ", expected: "blade runner"},
+		"Non-latin unchanged": {input: "七人の侍", expected: "七人の侍"},
+		"Empty":               {input: "", expected: ""},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Fold(tt.input))
+		})
+	}
+}
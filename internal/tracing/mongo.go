@@ -0,0 +1,140 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+
+	"github.com/yasv98/movies-api/internal/mongocommand"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxStatementLength truncates statements recorded on spans, as commands
+// such as inserts can be arbitrarily large.
+const maxStatementLength = 2048
+
+// ignoredCommandFields are added by the driver to every command and are of
+// no use when reading a trace.
+var ignoredCommandFields = map[string]bool{
+	"lsid":            true,
+	"$clusterTime":    true,
+	"$db":             true,
+	"txnNumber":       true,
+	"$readPreference": true,
+}
+
+// CommandMonitor records a client span for every command sent to MongoDB,
+// as a child of the span in the operation's context. Statements are
+// sanitized so that no values from documents or filters are recorded.
+func CommandMonitor() *event.CommandMonitor {
+	tracer := otel.Tracer("github.com/yasv98/movies-api/internal/tracing")
+
+	// Spans are kept until the command's finished event, which carries the
+	// same request ID.
+	var spans sync.Map
+
+	end := func(requestID int64, err string) {
+		value, ok := spans.LoadAndDelete(requestID)
+		if !ok {
+			return
+		}
+		span := value.(trace.Span)
+		if err != "" {
+			span.SetStatus(codes.Error, err)
+		}
+		span.End()
+	}
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			collection, _ := mongocommand.Collection(e.CommandName, e.Command)
+			name := e.CommandName
+			if collection != "" {
+				name += " " + collection
+			}
+
+			_, span := tracer.Start(ctx, name,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					attribute.String("db.system", "mongodb"),
+					attribute.String("db.name", e.DatabaseName),
+					attribute.String("db.operation", e.CommandName),
+					attribute.String("db.mongodb.collection", collection),
+					attribute.String("db.statement", SanitizeCommand(e.Command)),
+				),
+			)
+			spans.Store(e.RequestID, span)
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			end(e.RequestID, "")
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			end(e.RequestID, e.Failure)
+		},
+	}
+}
+
+// SanitizeCommand renders command as extended JSON with every value
+// replaced by "?", keeping only its shape. The command's first element,
+// naming the command and usually its collection, is kept as is.
+func SanitizeCommand(command bson.Raw) string {
+	elements, err := command.Elements()
+	if err != nil {
+		return ""
+	}
+
+	sanitized := make(bson.D, 0, len(elements))
+	for i, element := range elements {
+		key := element.Key()
+		if ignoredCommandFields[key] {
+			continue
+		}
+		if i == 0 {
+			sanitized = append(sanitized, bson.E{Key: key, Value: element.Value()})
+			continue
+		}
+		sanitized = append(sanitized, bson.E{Key: key, Value: sanitizeValue(element.Value())})
+	}
+
+	statement, err := bson.MarshalExtJSON(sanitized, false, false)
+	if err != nil {
+		return ""
+	}
+	if len(statement) > maxStatementLength {
+		statement = append(statement[:maxStatementLength], "..."...)
+	}
+
+	return string(statement)
+}
+
+func sanitizeValue(value bson.RawValue) interface{} {
+	switch value.Type {
+	case bsontype.EmbeddedDocument:
+		elements, err := value.Document().Elements()
+		if err != nil {
+			return "?"
+		}
+		sanitized := make(bson.D, 0, len(elements))
+		for _, element := range elements {
+			sanitized = append(sanitized, bson.E{Key: element.Key(), Value: sanitizeValue(element.Value())})
+		}
+		return sanitized
+	case bsontype.Array:
+		values, err := value.Array().Values()
+		if err != nil {
+			return "?"
+		}
+		sanitized := make(bson.A, 0, len(values))
+		for _, v := range values {
+			sanitized = append(sanitized, sanitizeValue(v))
+		}
+		return sanitized
+	}
+
+	return "?"
+}
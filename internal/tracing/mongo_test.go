@@ -0,0 +1,79 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSanitizeCommand(t *testing.T) {
+	tests := map[string]struct {
+		command  bson.D
+		expected string
+	}{
+		"Find with filter": {
+			command: bson.D{
+				{Key: "find", Value: "movies"},
+				{Key: "filter", Value: bson.D{{Key: "title", Value: bson.D{{Key: "$regex", Value: "Matrix"}}}}},
+				{Key: "limit", Value: 10},
+				{Key: "lsid", Value: bson.D{{Key: "id", Value: "session"}}},
+				{Key: "$db", Value: "sample_mflix"},
+			},
+			expected: `{"find":"movies","filter":{"title":{"$regex":"?"}},"limit":"?"}`,
+		},
+		"Insert documents": {
+			command: bson.D{
+				{Key: "insert", Value: "comments"},
+				{Key: "documents", Value: bson.A{bson.D{{Key: "email", Value: "a@example.com"}}}},
+			},
+			expected: `{"insert":"comments","documents":[{"email":"?"}]}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			raw, err := bson.Marshal(tt.command)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, SanitizeCommand(raw))
+		})
+	}
+}
+
+func TestCommandMonitor(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	monitor := CommandMonitor()
+	ctx := context.Background()
+
+	find, err := bson.Marshal(bson.D{{Key: "find", Value: "movies"}, {Key: "filter", Value: bson.D{{Key: "year", Value: 1999}}}})
+	require.NoError(t, err)
+
+	monitor.Started(ctx, &event.CommandStartedEvent{CommandName: "find", DatabaseName: "sample_mflix", Command: find, RequestID: 1})
+	monitor.Started(ctx, &event.CommandStartedEvent{CommandName: "find", DatabaseName: "sample_mflix", Command: find, RequestID: 2})
+	monitor.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: event.CommandFinishedEvent{RequestID: 1}})
+	monitor.Failed(ctx, &event.CommandFailedEvent{CommandFinishedEvent: event.CommandFinishedEvent{RequestID: 2}, Failure: "timeout"})
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "find movies", spans[0].Name())
+	assert.Contains(t, spans[0].Attributes(), attribute.String("db.statement", `{"find":"movies","filter":{"year":"?"}}`))
+	assert.Contains(t, spans[0].Attributes(), attribute.String("db.mongodb.collection", "movies"))
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, "timeout", spans[1].Status().Description)
+}
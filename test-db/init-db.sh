@@ -15,5 +15,8 @@ mongosh sample_mflix --eval '
 
     // Index to speed up comment lookups by movie and comment ID.
     db.comments.createIndex({"movie_id": 1, "_id": 1});
+
+    // Index to speed up reply lookups by parent comment.
+    db.comments.createIndex({"movie_id": 1, "parent_id": 1});
 '
 echo "Finished creating indexes"
\ No newline at end of file
@@ -2,17 +2,25 @@ package integration
 
 import (
 	"context"
+	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/steinfletcher/apitest"
 	"github.com/stretchr/testify/suite"
 	"github.com/yasv98/movies-api/internal/delivery/http/handler"
+	"github.com/yasv98/movies-api/internal/delivery/http/middleware"
 	"github.com/yasv98/movies-api/internal/delivery/http/routes"
+	"github.com/yasv98/movies-api/internal/domain"
+	"github.com/yasv98/movies-api/internal/health"
+	"github.com/yasv98/movies-api/internal/metrics"
 	"github.com/yasv98/movies-api/internal/repository/mongodb"
 	"github.com/yasv98/movies-api/internal/service"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -42,6 +50,8 @@ type IntegrationTestSuite struct {
 
 func (s *IntegrationTestSuite) SetupSuite() {
 	s.client, s.db = connectDatabase(context.Background())
+	s.Require().NoError(mongodb.EnsureIndexes(context.Background(), s.db))
+	s.Require().NoError(mongodb.SyncNormalizedFields(context.Background(), s.db))
 	s.app = newApp(s.db)
 	s.server = httptest.NewServer(s.app.Router)
 }
@@ -52,6 +62,70 @@ func (s *IntegrationTestSuite) SetupSuite() {
 // - Compare response data against test database records
 // - Add test cases for all errors and invalid requests
 
+func (s *IntegrationTestSuite) TestHealth() {
+	apitest.New("Liveness").
+		Handler(s.app.Router).
+		Get("/healthz").
+		Expect(s.T()).
+		Status(http.StatusOK).
+		End()
+
+	var report health.Report
+	apitest.New("Readiness").
+		Handler(s.app.Router).
+		Get("/readyz").
+		Expect(s.T()).
+		Status(http.StatusOK).
+		End().
+		JSON(&report)
+
+	s.Equal(health.StatusOK, report.Status)
+	s.Len(report.Checks, 2)
+}
+
+func (s *IntegrationTestSuite) TestMetrics() {
+	apitest.New("Request to record").
+		Handler(s.app.Router).
+		Get("/api/v1/movies/" + validMovieID).
+		Expect(s.T()).
+		Status(http.StatusOK).
+		End()
+
+	apitest.New("Metrics").
+		Handler(s.app.Router).
+		Get("/metrics").
+		Expect(s.T()).
+		Status(http.StatusOK).
+		Assert(func(res *http.Response, _ *http.Request) error {
+			body, err := io.ReadAll(res.Body)
+			if err != nil {
+				return err
+			}
+			s.Contains(string(body), `http_requests_total{method="GET",route="/api/v1/movies/:movieId",status="200"}`)
+			return nil
+		}).
+		End()
+}
+
+func (s *IntegrationTestSuite) TestRequestID() {
+	apitest.New("Inbound request ID is echoed").
+		Handler(s.app.Router).
+		Get("/healthz").
+		Header(middleware.RequestIDHeader, "req-123").
+		Expect(s.T()).
+		Status(http.StatusOK).
+		Header(middleware.RequestIDHeader, "req-123").
+		End()
+
+	apitest.New("Request ID is generated").
+		Handler(s.app.Router).
+		Get("/healthz").
+		Expect(s.T()).
+		Status(http.StatusOK).
+		HeaderPresent(middleware.RequestIDHeader).
+		End()
+}
+
 const validMovieID = "573a1390f29313caabcd4eaf"
 const invalidMovieID = "12345"
 const missingMovieID = "573a1390f29313caabcd4133"
@@ -65,6 +139,62 @@ func (s *IntegrationTestSuite) TestGetMovie_Valid() {
 		End()
 }
 
+func (s *IntegrationTestSuite) TestGetMovie_Fields() {
+	var movie map[string]interface{}
+
+	apitest.New("Get movie with selected fields").
+		Handler(s.app.Router).
+		Get("/api/v1/movies/"+validMovieID).
+		Query("fields", "title,imdb.rating").
+		Expect(s.T()).
+		Status(http.StatusOK).
+		End().
+		JSON(&movie)
+
+	s.ElementsMatch([]string{"_id", "title", "imdb"}, mapKeys(movie))
+	s.ElementsMatch([]string{"rating"}, mapKeys(movie["imdb"].(map[string]interface{})))
+
+	var movies []map[string]interface{}
+
+	apitest.New("Get movies with selected fields").
+		Handler(s.app.Router).
+		Get("/api/v1/movies").
+		Query("fields", "title,year").
+		Expect(s.T()).
+		Status(http.StatusOK).
+		End().
+		JSON(&movies)
+
+	s.Require().NotEmpty(movies)
+	for _, movie := range movies {
+		s.ElementsMatch([]string{"_id", "title", "year"}, mapKeys(movie))
+	}
+
+	apitest.New("Get movie with unknown field").
+		Handler(s.app.Router).
+		Get("/api/v1/movies/"+validMovieID).
+		Query("fields", "title,budget").
+		Expect(s.T()).
+		Status(http.StatusBadRequest).
+		End()
+
+	apitest.New("Get movies with unknown field").
+		Handler(s.app.Router).
+		Get("/api/v1/movies").
+		Query("fields", "imdb.score").
+		Expect(s.T()).
+		Status(http.StatusBadRequest).
+		End()
+}
+
+func mapKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
 func (s *IntegrationTestSuite) TestGetMovie_Invalid() {
 	apitest.New("Get movie with invalid ID").
 		Handler(s.app.Router).
@@ -81,6 +211,66 @@ func (s *IntegrationTestSuite) TestGetMovie_Invalid() {
 		End()
 }
 
+func (s *IntegrationTestSuite) TestGetSimilarMovies() {
+	apitest.New("Get similar movies").
+		Handler(s.app.Router).
+		Get("/api/v1/movies/"+validMovieID+"/similar").
+		Query("limit", "5").
+		Expect(s.T()).
+		Status(http.StatusOK).
+		End()
+
+	apitest.New("Get similar movies with limit out of range").
+		Handler(s.app.Router).
+		Get("/api/v1/movies/"+validMovieID+"/similar").
+		Query("limit", "500").
+		Expect(s.T()).
+		Status(http.StatusBadRequest).
+		End()
+
+	apitest.New("Get similar movies for non-existent movie").
+		Handler(s.app.Router).
+		Get("/api/v1/movies/" + missingMovieID + "/similar").
+		Expect(s.T()).
+		Status(http.StatusNotFound).
+		End()
+}
+
+func (s *IntegrationTestSuite) TestAutocomplete() {
+	var suggestions []struct {
+		Title string `json:"title"`
+	}
+
+	apitest.New("Autocomplete title ignoring case").
+		Handler(s.app.Router).
+		Get("/api/v1/movies/autocomplete").
+		Query("prefix", "BLACKSMITH").
+		Expect(s.T()).
+		Status(http.StatusOK).
+		End().
+		JSON(&suggestions)
+
+	s.Require().NotEmpty(suggestions)
+	s.Equal("Blacksmith Scene", suggestions[0].Title)
+
+	apitest.New("Autocomplete with empty prefix").
+		Handler(s.app.Router).
+		Get("/api/v1/movies/autocomplete").
+		Query("prefix", "  ").
+		Expect(s.T()).
+		Status(http.StatusBadRequest).
+		End()
+
+	apitest.New("Autocomplete with limit out of range").
+		Handler(s.app.Router).
+		Get("/api/v1/movies/autocomplete").
+		Query("prefix", "the").
+		Query("limit", "100").
+		Expect(s.T()).
+		Status(http.StatusBadRequest).
+		End()
+}
+
 func (s *IntegrationTestSuite) TestGetMovies_Valid() {
 	apitest.New("Get movies without any parameters").
 		Handler(s.app.Router).
@@ -117,6 +307,256 @@ func (s *IntegrationTestSuite) TestGetMovies_Valid() {
 		End()
 }
 
+func (s *IntegrationTestSuite) TestGetMovies_Facets() {
+	var result struct {
+		Movies []domain.Movie     `json:"movies"`
+		Facets domain.MovieFacets `json:"facets"`
+	}
+
+	apitest.New("Get movies with facets").
+		Handler(s.app.Router).
+		Get("/api/v1/movies").
+		Query("title", "Blacksmith").
+		Query("facets", "genres,decade,rated").
+		Expect(s.T()).
+		Status(http.StatusOK).
+		End().
+		JSON(&result)
+
+	s.Require().NotEmpty(result.Movies)
+	s.Len(result.Facets, 3)
+
+	decades := make([]string, 0, len(result.Facets[domain.MovieFacetDecade]))
+	for _, count := range result.Facets[domain.MovieFacetDecade] {
+		decades = append(decades, count.Value)
+	}
+	s.Contains(decades, "1890s")
+
+	genreTotal := 0
+	for _, count := range result.Facets[domain.MovieFacetGenres] {
+		genreTotal += count.Count
+	}
+	s.GreaterOrEqual(genreTotal, len(result.Movies))
+
+	apitest.New("Get movies with unknown facet").
+		Handler(s.app.Router).
+		Get("/api/v1/movies").
+		Query("facets", "genres,budget").
+		Expect(s.T()).
+		Status(http.StatusBadRequest).
+		End()
+}
+
+func (s *IntegrationTestSuite) TestGetMoviesByIDs() {
+	const otherMovieID = "573a1390f29313caabcd4135"
+
+	var batch struct {
+		Movies []struct {
+			ID string `json:"_id"`
+		} `json:"movies"`
+		NotFound []string `json:"not_found"`
+	}
+
+	apitest.New("Get movies by IDs").
+		Handler(s.app.Router).
+		Get("/api/v1/movies").
+		Query("ids", otherMovieID+","+missingMovieID+","+validMovieID+","+otherMovieID).
+		Expect(s.T()).
+		Status(http.StatusOK).
+		End().
+		JSON(&batch)
+
+	s.Require().Len(batch.Movies, 2)
+	s.Equal(otherMovieID, batch.Movies[0].ID)
+	s.Equal(validMovieID, batch.Movies[1].ID)
+	s.Equal([]string{missingMovieID}, batch.NotFound)
+
+	apitest.New("Batch get movies").
+		Handler(s.app.Router).
+		Post("/api/v1/movies:batchGet").
+		JSON(map[string]interface{}{
+			"ids":    []string{validMovieID, missingMovieID},
+			"fields": []string{"title"},
+		}).
+		Expect(s.T()).
+		Status(http.StatusOK).
+		End().
+		JSON(&batch)
+
+	s.Require().Len(batch.Movies, 1)
+	s.Equal(validMovieID, batch.Movies[0].ID)
+	s.Equal([]string{missingMovieID}, batch.NotFound)
+
+	apitest.New("Get movies by invalid ID").
+		Handler(s.app.Router).
+		Get("/api/v1/movies").
+		Query("ids", validMovieID+","+invalidMovieID).
+		Expect(s.T()).
+		Status(http.StatusBadRequest).
+		End()
+
+	ids := make([]string, domain.MaxMovieBatchSize+1)
+	for i := range ids {
+		ids[i] = validMovieID
+	}
+	apitest.New("Batch get too many movies").
+		Handler(s.app.Router).
+		Post("/api/v1/movies:batchGet").
+		JSON(map[string]interface{}{"ids": ids}).
+		Expect(s.T()).
+		Status(http.StatusBadRequest).
+		End()
+
+	apitest.New("Unknown custom method").
+		Handler(s.app.Router).
+		Post("/api/v1/movies:batchDelete").
+		JSON(map[string]interface{}{"ids": []string{validMovieID}}).
+		Expect(s.T()).
+		Status(http.StatusNotFound).
+		End()
+}
+
+func (s *IntegrationTestSuite) TestPeople() {
+	var people []domain.Person
+	apitest.New("Search people by surname ignoring case").
+		Handler(s.app.Router).
+		Get("/api/v1/people").
+		Query("name", "CHAPLIN").
+		Expect(s.T()).
+		Status(http.StatusOK).
+		End().
+		JSON(&people)
+
+	s.Require().NotEmpty(people)
+	s.Equal("Charles Chaplin", people[0].Name)
+	s.ElementsMatch([]domain.PersonRole{domain.PersonRoleDirector, domain.PersonRoleCast}, people[0].Roles)
+	s.LessOrEqual(people[0].FirstYear, people[0].LastYear)
+
+	var filmography domain.Filmography
+	apitest.New("Get person movies by rating").
+		Handler(s.app.Router).
+		Get("/api/v1/people/charles%20chaplin/movies").
+		Query("sort", "rating").
+		Expect(s.T()).
+		Status(http.StatusOK).
+		End().
+		JSON(&filmography)
+
+	s.Equal("Charles Chaplin", filmography.Person.Name)
+	s.Require().NotEmpty(filmography.Movies)
+	for i, movie := range filmography.Movies {
+		s.NotEmpty(movie.Roles)
+		if i > 0 {
+			s.GreaterOrEqual(filmography.Movies[i-1].IMDB.Rating, movie.IMDB.Rating)
+		}
+	}
+
+	apitest.New("Get movies of unknown person").
+		Handler(s.app.Router).
+		Get("/api/v1/people/nobody%20at%20all/movies").
+		Expect(s.T()).
+		Status(http.StatusNotFound).
+		End()
+
+	apitest.New("Search people without a name").
+		Handler(s.app.Router).
+		Get("/api/v1/people").
+		Expect(s.T()).
+		Status(http.StatusBadRequest).
+		End()
+}
+
+func (s *IntegrationTestSuite) TestReferenceValues() {
+	for _, path := range []string{"/api/v1/genres", "/api/v1/countries", "/api/v1/languages", "/api/v1/ratings"} {
+		var values []domain.ReferenceValue
+		apitest.New("Get reference values from " + path).
+			Handler(s.app.Router).
+			Get(path).
+			Expect(s.T()).
+			Status(http.StatusOK).
+			End().
+			JSON(&values)
+
+		s.Require().NotEmpty(values)
+		for i := 1; i < len(values); i++ {
+			s.GreaterOrEqual(values[i-1].Count, values[i].Count)
+		}
+	}
+}
+
+func (s *IntegrationTestSuite) TestStats() {
+	var years []domain.YearCount
+	apitest.New("Movies per year").
+		Handler(s.app.Router).
+		Get("/api/v1/stats/movies-per-year").
+		Expect(s.T()).
+		Status(http.StatusOK).
+		End().
+		JSON(&years)
+	s.Require().NotEmpty(years)
+	for i := 1; i < len(years); i++ {
+		s.Less(years[i-1].Year, years[i].Year)
+	}
+
+	var genres []domain.GenreCount
+	apitest.New("Genre distribution").
+		Handler(s.app.Router).
+		Get("/api/v1/stats/genres").
+		Expect(s.T()).
+		Status(http.StatusOK).
+		End().
+		JSON(&genres)
+	s.Require().NotEmpty(genres)
+	s.GreaterOrEqual(genres[0].Count, genres[len(genres)-1].Count)
+
+	apitest.New("Ratings by genre and decade").
+		Handler(s.app.Router).
+		Get("/api/v1/stats/ratings/genre-decade").
+		Expect(s.T()).
+		Status(http.StatusOK).
+		End()
+
+	var directors []domain.DirectorRating
+	apitest.New("Top directors").
+		Handler(s.app.Router).
+		Get("/api/v1/stats/directors/top").
+		Query("min_votes", "50000").
+		Query("limit", "5").
+		Expect(s.T()).
+		Status(http.StatusOK).
+		End().
+		JSON(&directors)
+	s.LessOrEqual(len(directors), 5)
+	for _, director := range directors {
+		s.GreaterOrEqual(director.Votes, 50000)
+	}
+
+	apitest.New("Comment activity by year").
+		Handler(s.app.Router).
+		Get("/api/v1/stats/comments/activity").
+		Query("interval", "year").
+		Query("from", "2000-01-01T00:00:00Z").
+		Expect(s.T()).
+		Status(http.StatusOK).
+		End()
+
+	apitest.New("Comment activity with invalid interval").
+		Handler(s.app.Router).
+		Get("/api/v1/stats/comments/activity").
+		Query("interval", "fortnight").
+		Expect(s.T()).
+		Status(http.StatusBadRequest).
+		End()
+
+	apitest.New("Top directors with negative min votes").
+		Handler(s.app.Router).
+		Get("/api/v1/stats/directors/top").
+		Query("min_votes", "-1").
+		Expect(s.T()).
+		Status(http.StatusBadRequest).
+		End()
+}
+
 func (s *IntegrationTestSuite) TestGetMovies_Invalid() {
 	apitest.New("Get movies with invalid page parameter").
 		Handler(s.app.Router).
@@ -126,12 +566,352 @@ func (s *IntegrationTestSuite) TestGetMovies_Invalid() {
 		Status(http.StatusBadRequest).
 		End()
 
-	apitest.New("Get movies with invalid limit parameter").
+	apitest.New("Get movies with invalid limit parameter").
+		Handler(s.app.Router).
+		Get("/api/v1/movies").
+		Query("limit", "invalid").
+		Expect(s.T()).
+		Status(http.StatusBadRequest).
+		End()
+
+	apitest.New("Get movies with invalid sort parameter").
+		Handler(s.app.Router).
+		Get("/api/v1/movies").
+		Query("sort", "popularity").
+		Expect(s.T()).
+		Status(http.StatusBadRequest).
+		End()
+
+	apitest.New("Get movies with invalid community rating filter").
+		Handler(s.app.Router).
+		Get("/api/v1/movies").
+		Query("min_community_rating", "high").
+		Expect(s.T()).
+		Status(http.StatusBadRequest).
+		End()
+}
+
+func (s *IntegrationTestSuite) TestMovieRating() {
+	apitest.New("Rate movie").
+		Handler(s.app.Router).
+		Put("/api/v1/movies/"+validMovieID+"/rating").
+		Header("X-User-ID", "integration-user").
+		JSON(map[string]int{"score": 8}).
+		Expect(s.T()).
+		Status(http.StatusOK).
+		End()
+
+	apitest.New("Get own rating").
+		Handler(s.app.Router).
+		Get("/api/v1/movies/"+validMovieID+"/rating").
+		Header("X-User-ID", "integration-user").
+		Expect(s.T()).
+		Status(http.StatusOK).
+		End()
+
+	apitest.New("Get movies sorted by community rating").
+		Handler(s.app.Router).
+		Get("/api/v1/movies").
+		Query("sort", "community_rating").
+		Query("min_community_rating", "5").
+		Query("min_community_votes", "1").
+		Expect(s.T()).
+		Status(http.StatusOK).
+		End()
+
+	apitest.New("Delete own rating").
+		Handler(s.app.Router).
+		Delete("/api/v1/movies/"+validMovieID+"/rating").
+		Header("X-User-ID", "integration-user").
+		Expect(s.T()).
+		Status(http.StatusNoContent).
+		End()
+}
+
+func (s *IntegrationTestSuite) TestMovieRating_Invalid() {
+	apitest.New("Rate movie without a user").
+		Handler(s.app.Router).
+		Put("/api/v1/movies/" + validMovieID + "/rating").
+		JSON(map[string]int{"score": 8}).
+		Expect(s.T()).
+		Status(http.StatusUnauthorized).
+		End()
+
+	apitest.New("Rate movie out of range").
+		Handler(s.app.Router).
+		Put("/api/v1/movies/"+validMovieID+"/rating").
+		Header("X-User-ID", "integration-user").
+		JSON(map[string]int{"score": 11}).
+		Expect(s.T()).
+		Status(http.StatusBadRequest).
+		End()
+
+	apitest.New("Rate non-existent movie").
+		Handler(s.app.Router).
+		Put("/api/v1/movies/"+missingMovieID+"/rating").
+		Header("X-User-ID", "integration-user").
+		JSON(map[string]int{"score": 8}).
+		Expect(s.T()).
+		Status(http.StatusNotFound).
+		End()
+
+	apitest.New("Get rating that does not exist").
+		Handler(s.app.Router).
+		Get("/api/v1/movies/"+validMovieID+"/rating").
+		Header("X-User-ID", "user-without-ratings").
+		Expect(s.T()).
+		Status(http.StatusNotFound).
+		End()
+}
+
+func (s *IntegrationTestSuite) TestWatchlist() {
+	const user = "watchlist-user"
+	movieIDs := []string{validMovieID, "573a1390f29313caabcd4135"}
+
+	// Clear out movies left over from a previous run.
+	for _, movieID := range movieIDs {
+		apitest.New("Remove leftover watchlist movie").
+			Handler(s.app.Router).
+			Delete("/api/v1/me/watchlist/"+movieID).
+			Header("X-User-ID", user).
+			Expect(s.T()).
+			End()
+	}
+
+	for _, movieID := range movieIDs {
+		apitest.New("Add movie to watchlist").
+			Handler(s.app.Router).
+			Post("/api/v1/me/watchlist").
+			Header("X-User-ID", user).
+			JSON(map[string]string{"movie_id": movieID}).
+			Expect(s.T()).
+			Status(http.StatusCreated).
+			End()
+	}
+
+	apitest.New("Add duplicate movie to watchlist").
+		Handler(s.app.Router).
+		Post("/api/v1/me/watchlist").
+		Header("X-User-ID", user).
+		JSON(map[string]string{"movie_id": validMovieID}).
+		Expect(s.T()).
+		Status(http.StatusConflict).
+		End()
+
+	apitest.New("Reorder watchlist").
+		Handler(s.app.Router).
+		Put("/api/v1/me/watchlist/order").
+		Header("X-User-ID", user).
+		JSON(map[string][]string{"movie_ids": {movieIDs[1], movieIDs[0]}}).
+		Expect(s.T()).
+		Status(http.StatusNoContent).
+		End()
+
+	var items []struct {
+		MovieID string `json:"movie_id"`
+	}
+
+	apitest.New("Get watchlist").
+		Handler(s.app.Router).
+		Get("/api/v1/me/watchlist").
+		Header("X-User-ID", user).
+		Expect(s.T()).
+		Status(http.StatusOK).
+		End().
+		JSON(&items)
+
+	s.Require().Len(items, 2)
+	s.Equal(movieIDs[1], items[0].MovieID)
+	s.Equal(movieIDs[0], items[1].MovieID)
+
+	apitest.New("Remove movie from watchlist").
+		Handler(s.app.Router).
+		Delete("/api/v1/me/watchlist/"+validMovieID).
+		Header("X-User-ID", user).
+		Expect(s.T()).
+		Status(http.StatusNoContent).
+		End()
+}
+
+func (s *IntegrationTestSuite) TestWatchlist_ConcurrentAdds() {
+	ctx := context.Background()
+	repo := mongodb.NewUserListRepository(s.db)
+	user := "concurrent-user-" + primitive.NewObjectID().Hex()
+
+	const adds = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, adds)
+	for i := 0; i < adds; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- repo.Add(ctx, &domain.UserListEntry{
+				UserID:  user,
+				List:    domain.Watchlist,
+				MovieID: primitive.NewObjectID(),
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		s.Require().NoError(err)
+	}
+
+	entries, err := repo.List(ctx, user, domain.Watchlist, 1, adds)
+	s.Require().NoError(err)
+	s.Require().Len(entries, adds)
+
+	// Every concurrent add got a position of its own.
+	positions := make(map[int]bool, adds)
+	for _, entry := range entries {
+		positions[entry.Position] = true
+	}
+	s.Len(positions, adds)
+}
+
+func (s *IntegrationTestSuite) TestFavorites_Invalid() {
+	apitest.New("Get favorites without a user").
+		Handler(s.app.Router).
+		Get("/api/v1/me/favorites").
+		Expect(s.T()).
+		Status(http.StatusUnauthorized).
+		End()
+
+	apitest.New("Add non-existent movie to favorites").
+		Handler(s.app.Router).
+		Post("/api/v1/me/favorites").
+		Header("X-User-ID", "integration-user").
+		JSON(map[string]string{"movie_id": missingMovieID}).
+		Expect(s.T()).
+		Status(http.StatusNotFound).
+		End()
+
+	apitest.New("Reorder favorites with unknown movie").
+		Handler(s.app.Router).
+		Put("/api/v1/me/favorites/order").
+		Header("X-User-ID", "integration-user").
+		JSON(map[string][]string{"movie_ids": {missingMovieID}}).
+		Expect(s.T()).
+		Status(http.StatusBadRequest).
+		End()
+
+	apitest.New("Remove movie not in favorites").
+		Handler(s.app.Router).
+		Delete("/api/v1/me/favorites/"+missingMovieID).
+		Header("X-User-ID", "integration-user").
+		Expect(s.T()).
+		Status(http.StatusNotFound).
+		End()
+}
+
+func (s *IntegrationTestSuite) TestCollections() {
+	const owner = "collection-editor"
+	collection := map[string]interface{}{
+		"title":       "Early cinema",
+		"description": "Films from the dawn of cinema",
+		"movie_ids":   []string{"573a1390f29313caabcd4135", validMovieID},
+		"visibility":  "unlisted",
+	}
+
+	var created struct {
+		ID string `json:"id"`
+	}
+
+	apitest.New("Create collection").
+		Handler(s.app.Router).
+		Post("/api/v1/collections").
+		Header("X-User-ID", owner).
+		JSON(collection).
+		Expect(s.T()).
+		Status(http.StatusCreated).
+		End().
+		JSON(&created)
+
+	var resolved struct {
+		Movies []struct {
+			ID string `json:"_id"`
+		} `json:"movies"`
+	}
+
+	apitest.New("Get unlisted collection anonymously").
+		Handler(s.app.Router).
+		Get("/api/v1/collections/" + created.ID).
+		Expect(s.T()).
+		Status(http.StatusOK).
+		End().
+		JSON(&resolved)
+
+	s.Require().Len(resolved.Movies, 2)
+	s.Equal("573a1390f29313caabcd4135", resolved.Movies[0].ID)
+	s.Equal(validMovieID, resolved.Movies[1].ID)
+
+	collection["visibility"] = "private"
+	apitest.New("Update collection").
+		Handler(s.app.Router).
+		Put("/api/v1/collections/"+created.ID).
+		Header("X-User-ID", owner).
+		JSON(collection).
+		Expect(s.T()).
+		Status(http.StatusOK).
+		End()
+
+	apitest.New("Get private collection as another user").
+		Handler(s.app.Router).
+		Get("/api/v1/collections/"+created.ID).
+		Header("X-User-ID", "someone-else").
+		Expect(s.T()).
+		Status(http.StatusNotFound).
+		End()
+
+	apitest.New("List own collections").
+		Handler(s.app.Router).
+		Get("/api/v1/me/collections").
+		Header("X-User-ID", owner).
+		Expect(s.T()).
+		Status(http.StatusOK).
+		End()
+
+	apitest.New("Delete collection").
+		Handler(s.app.Router).
+		Delete("/api/v1/collections/"+created.ID).
+		Header("X-User-ID", owner).
+		Expect(s.T()).
+		Status(http.StatusNoContent).
+		End()
+}
+
+func (s *IntegrationTestSuite) TestCollections_Invalid() {
+	apitest.New("List public collections").
+		Handler(s.app.Router).
+		Get("/api/v1/collections").
+		Expect(s.T()).
+		Status(http.StatusOK).
+		End()
+
+	apitest.New("Create collection without a title").
+		Handler(s.app.Router).
+		Post("/api/v1/collections").
+		Header("X-User-ID", "collection-editor").
+		JSON(map[string]interface{}{"movie_ids": []string{validMovieID}}).
+		Expect(s.T()).
+		Status(http.StatusBadRequest).
+		End()
+
+	apitest.New("Create collection with non-existent movie").
+		Handler(s.app.Router).
+		Post("/api/v1/collections").
+		Header("X-User-ID", "collection-editor").
+		JSON(map[string]interface{}{"title": "Missing", "movie_ids": []string{missingMovieID}}).
+		Expect(s.T()).
+		Status(http.StatusBadRequest).
+		End()
+
+	apitest.New("Get non-existent collection").
 		Handler(s.app.Router).
-		Get("/api/v1/movies").
-		Query("limit", "invalid").
+		Get("/api/v1/collections/" + missingMovieID).
 		Expect(s.T()).
-		Status(http.StatusBadRequest).
+		Status(http.StatusNotFound).
 		End()
 }
 
@@ -209,6 +989,57 @@ func (s *IntegrationTestSuite) TestCreateComment_Valid() {
 		End()
 }
 
+func (s *IntegrationTestSuite) TestCreateComment_IdempotencyKey() {
+	movieID := "573a1390f29313caabcd4135"
+	// A fresh key and text each run, so re-runs are not replays or
+	// duplicates.
+	key := primitive.NewObjectID().Hex()
+	comment := map[string]string{
+		"name":  "John Doe",
+		"email": "john@example.com",
+		"text":  "Worth watching twice " + key,
+	}
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	apitest.New("Create comment with idempotency key").
+		Handler(s.app.Router).
+		Post("/api/v1/movies/"+movieID+"/comments").
+		Header(handler.IdempotencyKeyHeader, key).
+		JSON(comment).
+		Expect(s.T()).
+		Status(http.StatusCreated).
+		HeaderNotPresent(handler.IdempotentReplayedHeader).
+		End().
+		JSON(&created)
+
+	var replayed struct {
+		ID string `json:"id"`
+	}
+	apitest.New("Retry replays the original response").
+		Handler(s.app.Router).
+		Post("/api/v1/movies/"+movieID+"/comments").
+		Header(handler.IdempotencyKeyHeader, key).
+		JSON(comment).
+		Expect(s.T()).
+		Status(http.StatusCreated).
+		Header(handler.IdempotentReplayedHeader, "true").
+		End().
+		JSON(&replayed)
+	s.Equal(created.ID, replayed.ID)
+
+	comment["text"] = "Changed my mind " + key
+	apitest.New("Key reused with a different body").
+		Handler(s.app.Router).
+		Post("/api/v1/movies/"+movieID+"/comments").
+		Header(handler.IdempotencyKeyHeader, key).
+		JSON(comment).
+		Expect(s.T()).
+		Status(http.StatusUnprocessableEntity).
+		End()
+}
+
 func (s *IntegrationTestSuite) TestCreateComment_Invalid() {
 	invalidMovieID := "12345"
 	validComment := map[string]string{
@@ -276,6 +1107,392 @@ func (s *IntegrationTestSuite) TestDeleteComment() {
 		End()
 }
 
+func (s *IntegrationTestSuite) TestCommentReplies() {
+	movieID := "573a1390f29313caabcd4135"
+	comment := map[string]string{
+		"name":  "John Doe",
+		"email": "john@example.com",
+		"text":  "Great movie!",
+	}
+
+	var parent struct {
+		CommentID string `json:"id"`
+	}
+
+	apitest.New("Create parent comment").
+		Handler(s.app.Router).
+		Post("/api/v1/movies/" + movieID + "/comments").
+		JSON(comment).
+		Expect(s.T()).
+		Status(http.StatusCreated).
+		End().
+		JSON(&parent)
+
+	reply := map[string]string{
+		"name":      "Jane Doe",
+		"email":     "jane@example.com",
+		"text":      "Agreed!",
+		"parent_id": parent.CommentID,
+	}
+
+	apitest.New("Reply to comment").
+		Handler(s.app.Router).
+		Post("/api/v1/movies/" + movieID + "/comments").
+		JSON(reply).
+		Expect(s.T()).
+		Status(http.StatusCreated).
+		End()
+
+	apitest.New("Get comment replies").
+		Handler(s.app.Router).
+		Get("/api/v1/movies/" + movieID + "/comments/" + parent.CommentID + "/replies").
+		Expect(s.T()).
+		Status(http.StatusOK).
+		End()
+
+	apitest.New("Get movie comments as a tree").
+		Handler(s.app.Router).
+		Get("/api/v1/movies/"+movieID+"/comments").
+		Query("view", "tree").
+		Expect(s.T()).
+		Status(http.StatusOK).
+		End()
+
+	apitest.New("Get movie comments flattened with depth").
+		Handler(s.app.Router).
+		Get("/api/v1/movies/"+movieID+"/comments").
+		Query("view", "flat").
+		Expect(s.T()).
+		Status(http.StatusOK).
+		End()
+}
+
+func (s *IntegrationTestSuite) TestCommentReplies_Invalid() {
+	reply := map[string]string{
+		"name":      "Jane Doe",
+		"email":     "jane@example.com",
+		"text":      "Agreed!",
+		"parent_id": validCommentID,
+	}
+
+	apitest.New("Reply to comment from a different movie").
+		Handler(s.app.Router).
+		Post("/api/v1/movies/573a1390f29313caabcd4135/comments").
+		JSON(reply).
+		Expect(s.T()).
+		Status(http.StatusBadRequest).
+		End()
+
+	apitest.New("Get replies for non-existent comment").
+		Handler(s.app.Router).
+		Get("/api/v1/movies/" + validMovieID + "/comments/" + missingCommentID + "/replies").
+		Expect(s.T()).
+		Status(http.StatusNotFound).
+		End()
+
+	apitest.New("Get movie comments with invalid view").
+		Handler(s.app.Router).
+		Get("/api/v1/movies/"+validMovieID+"/comments").
+		Query("view", "sideways").
+		Expect(s.T()).
+		Status(http.StatusBadRequest).
+		End()
+}
+
+func (s *IntegrationTestSuite) TestCommentReactions() {
+	apitest.New("React to comment").
+		Handler(s.app.Router).
+		Post("/api/v1/movies/"+validMovieID+"/comments/"+validCommentID+"/reactions").
+		Header("X-User-ID", "integration-user").
+		JSON(map[string]string{"type": "like"}).
+		Expect(s.T()).
+		Status(http.StatusOK).
+		End()
+
+	apitest.New("React to comment again with the same type").
+		Handler(s.app.Router).
+		Post("/api/v1/movies/"+validMovieID+"/comments/"+validCommentID+"/reactions").
+		Header("X-User-ID", "integration-user").
+		JSON(map[string]string{"type": "like"}).
+		Expect(s.T()).
+		Status(http.StatusOK).
+		End()
+
+	apitest.New("Get movie comments sorted by popularity").
+		Handler(s.app.Router).
+		Get("/api/v1/movies/"+validMovieID+"/comments").
+		Query("sort", "top").
+		Expect(s.T()).
+		Status(http.StatusOK).
+		End()
+
+	apitest.New("Remove reaction").
+		Handler(s.app.Router).
+		Delete("/api/v1/movies/"+validMovieID+"/comments/"+validCommentID+"/reactions").
+		Header("X-User-ID", "integration-user").
+		Expect(s.T()).
+		Status(http.StatusNoContent).
+		End()
+}
+
+func (s *IntegrationTestSuite) TestCommentReactions_Invalid() {
+	apitest.New("React without a user").
+		Handler(s.app.Router).
+		Post("/api/v1/movies/" + validMovieID + "/comments/" + validCommentID + "/reactions").
+		JSON(map[string]string{"type": "like"}).
+		Expect(s.T()).
+		Status(http.StatusUnauthorized).
+		End()
+
+	apitest.New("React with unknown type").
+		Handler(s.app.Router).
+		Post("/api/v1/movies/"+validMovieID+"/comments/"+validCommentID+"/reactions").
+		Header("X-User-ID", "integration-user").
+		JSON(map[string]string{"type": "meh"}).
+		Expect(s.T()).
+		Status(http.StatusBadRequest).
+		End()
+
+	apitest.New("React to non-existent comment").
+		Handler(s.app.Router).
+		Post("/api/v1/movies/"+validMovieID+"/comments/"+missingCommentID+"/reactions").
+		Header("X-User-ID", "integration-user").
+		JSON(map[string]string{"type": "like"}).
+		Expect(s.T()).
+		Status(http.StatusNotFound).
+		End()
+
+	apitest.New("Get movie comments with invalid sort").
+		Handler(s.app.Router).
+		Get("/api/v1/movies/"+validMovieID+"/comments").
+		Query("sort", "bottom").
+		Expect(s.T()).
+		Status(http.StatusBadRequest).
+		End()
+}
+
+const testModeratorID = "integration-moderator"
+
+func (s *IntegrationTestSuite) TestCommentModeration() {
+	movieID := "573a1390f29313caabcd4135"
+	comment := map[string]string{
+		"name":  "John Doe",
+		"email": "john@example.com",
+		"text":  "Terrible comment",
+	}
+
+	var created struct {
+		CommentID string `json:"id"`
+	}
+
+	apitest.New("Create comment to flag").
+		Handler(s.app.Router).
+		Post("/api/v1/movies/" + movieID + "/comments").
+		JSON(comment).
+		Expect(s.T()).
+		Status(http.StatusCreated).
+		End().
+		JSON(&created)
+
+	// Reaching the flag threshold hides the comment.
+	for _, user := range []string{"flagger-1", "flagger-2", "flagger-3"} {
+		apitest.New("Flag comment").
+			Handler(s.app.Router).
+			Post("/api/v1/movies/"+movieID+"/comments/"+created.CommentID+"/flags").
+			Header("X-User-ID", user).
+			JSON(map[string]string{"reason": "abusive"}).
+			Expect(s.T()).
+			Status(http.StatusAccepted).
+			End()
+	}
+
+	apitest.New("Hidden comment is not returned").
+		Handler(s.app.Router).
+		Get("/api/v1/movies/" + movieID + "/comments/" + created.CommentID).
+		Expect(s.T()).
+		Status(http.StatusNotFound).
+		End()
+
+	apitest.New("Get moderation queue").
+		Handler(s.app.Router).
+		Get("/api/v1/moderation/queue").
+		Header("X-User-ID", testModeratorID).
+		Expect(s.T()).
+		Status(http.StatusOK).
+		End()
+
+	apitest.New("Approve comment").
+		Handler(s.app.Router).
+		Post("/api/v1/moderation/comments/"+created.CommentID+"/approve").
+		Header("X-User-ID", testModeratorID).
+		JSON(map[string]string{"note": "Not abusive"}).
+		Expect(s.T()).
+		Status(http.StatusOK).
+		End()
+
+	apitest.New("Approved comment is returned").
+		Handler(s.app.Router).
+		Get("/api/v1/movies/" + movieID + "/comments/" + created.CommentID).
+		Expect(s.T()).
+		Status(http.StatusOK).
+		End()
+
+	// Decided comments have left the queue, so a second decision, such as
+	// one racing the first, is refused.
+	apitest.New("Reject approved comment").
+		Handler(s.app.Router).
+		Post("/api/v1/moderation/comments/"+created.CommentID+"/reject").
+		Header("X-User-ID", testModeratorID).
+		Expect(s.T()).
+		Status(http.StatusConflict).
+		End()
+}
+
+func (s *IntegrationTestSuite) TestCommentModeration_Invalid() {
+	// The first flag may already exist from a previous run.
+	apitest.New("Flag comment").
+		Handler(s.app.Router).
+		Post("/api/v1/movies/"+validMovieID+"/comments/"+validCommentID+"/flags").
+		Header("X-User-ID", "repeat-flagger").
+		JSON(map[string]string{"reason": "spam"}).
+		Expect(s.T()).
+		End()
+
+	apitest.New("Flag comment twice").
+		Handler(s.app.Router).
+		Post("/api/v1/movies/"+validMovieID+"/comments/"+validCommentID+"/flags").
+		Header("X-User-ID", "repeat-flagger").
+		JSON(map[string]string{"reason": "spam"}).
+		Expect(s.T()).
+		Status(http.StatusConflict).
+		End()
+
+	apitest.New("Flag comment without reason").
+		Handler(s.app.Router).
+		Post("/api/v1/movies/"+validMovieID+"/comments/"+validCommentID+"/flags").
+		Header("X-User-ID", "integration-user").
+		JSON(map[string]string{}).
+		Expect(s.T()).
+		Status(http.StatusBadRequest).
+		End()
+
+	apitest.New("Get moderation queue as non-moderator").
+		Handler(s.app.Router).
+		Get("/api/v1/moderation/queue").
+		Header("X-User-ID", "integration-user").
+		Expect(s.T()).
+		Status(http.StatusForbidden).
+		End()
+
+	apitest.New("Reject non-existent comment").
+		Handler(s.app.Router).
+		Post("/api/v1/moderation/comments/"+missingCommentID+"/reject").
+		Header("X-User-ID", testModeratorID).
+		Expect(s.T()).
+		Status(http.StatusNotFound).
+		End()
+}
+
+func (s *IntegrationTestSuite) TestUpdateComment_Moderated() {
+	movieID := "573a1390f29313caabcd4135"
+	comment := map[string]string{
+		"name":  "John Doe",
+		"email": "john@example.com",
+		"text":  "Terrible comment",
+	}
+
+	var created struct {
+		CommentID string `json:"id"`
+	}
+
+	apitest.New("Create comment to hide").
+		Handler(s.app.Router).
+		Post("/api/v1/movies/" + movieID + "/comments").
+		JSON(comment).
+		Expect(s.T()).
+		Status(http.StatusCreated).
+		End().
+		JSON(&created)
+
+	for _, user := range []string{"flagger-1", "flagger-2", "flagger-3"} {
+		apitest.New("Flag comment").
+			Handler(s.app.Router).
+			Post("/api/v1/movies/"+movieID+"/comments/"+created.CommentID+"/flags").
+			Header("X-User-ID", user).
+			JSON(map[string]string{"reason": "abusive"}).
+			Expect(s.T()).
+			Status(http.StatusAccepted).
+			End()
+	}
+
+	// An edit held by the content filter must not move the comment from
+	// hidden back into the pending queue.
+	held := map[string]string{
+		"name":  "John Doe",
+		"email": "john@example.com",
+		"text":  "See https://example.com and https://example.org",
+	}
+
+	apitest.New("Edit hidden comment").
+		Handler(s.app.Router).
+		Put("/api/v1/movies/" + movieID + "/comments/" + created.CommentID).
+		JSON(held).
+		Expect(s.T()).
+		Status(http.StatusConflict).
+		End()
+
+	apitest.New("Reject comment").
+		Handler(s.app.Router).
+		Post("/api/v1/moderation/comments/"+created.CommentID+"/reject").
+		Header("X-User-ID", testModeratorID).
+		Expect(s.T()).
+		Status(http.StatusOK).
+		End()
+
+	apitest.New("Edit rejected comment").
+		Handler(s.app.Router).
+		Put("/api/v1/movies/" + movieID + "/comments/" + created.CommentID).
+		JSON(held).
+		Expect(s.T()).
+		Status(http.StatusConflict).
+		End()
+
+	apitest.New("Rejected comment is not returned").
+		Handler(s.app.Router).
+		Get("/api/v1/movies/" + movieID + "/comments/" + created.CommentID).
+		Expect(s.T()).
+		Status(http.StatusNotFound).
+		End()
+}
+
+func (s *IntegrationTestSuite) TestCreateComment_ContentFilter() {
+	movieID := "573a1390f29313caabcd4135"
+
+	apitest.New("Create comment with blocked words").
+		Handler(s.app.Router).
+		Post("/api/v1/movies/" + movieID + "/comments").
+		JSON(map[string]string{
+			"name":  "Spammer",
+			"email": "spammer@example.com",
+			"text":  "Buy now!",
+		}).
+		Expect(s.T()).
+		Status(http.StatusUnprocessableEntity).
+		End()
+
+	apitest.New("Create comment with too many links").
+		Handler(s.app.Router).
+		Post("/api/v1/movies/" + movieID + "/comments").
+		JSON(map[string]string{
+			"name":  "Linker",
+			"email": "linker@example.com",
+			"text":  "See https://example.com and https://example.org",
+		}).
+		Expect(s.T()).
+		Status(http.StatusAccepted).
+		End()
+}
+
 // TODO: Use mongo DB test container and seed with deterministic data.
 func connectDatabase(ctx context.Context) (*mongo.Client, *mongo.Database) {
 	// Connect to existing Docker container.
@@ -296,18 +1513,77 @@ func newApp(db *mongo.Database) *application {
 	// Repository.
 	movieRepo := mongodb.NewMovieRepository(db)
 	commentRepo := mongodb.NewCommentRepository(db)
+	reactionRepo := mongodb.NewReactionRepository(db)
+	flagRepo := mongodb.NewFlagRepository(db)
+	moderationRepo := mongodb.NewModerationRepository(db)
+	ratingRepo := mongodb.NewRatingRepository(db)
+	userListRepo := mongodb.NewUserListRepository(db)
+	collectionRepo := mongodb.NewCollectionRepository(db)
+	statsRepo := mongodb.NewStatsRepository(db)
+	personRepo := mongodb.NewPersonRepository(db)
+	referenceRepo := mongodb.NewReferenceRepository(db)
+	idempotencyRepo := mongodb.NewIdempotencyRepository(db)
 
 	// Service.
-	movieUsecase := service.NewMovieService(movieRepo)
-	commentUsecase := service.NewCommentService(commentRepo)
+	movieUsecase := service.NewMovieService(movieRepo, service.NewAggregationRecommender(movieRepo))
+	ratingUsecase := service.NewRatingService(ratingRepo, movieRepo)
+	userListUsecase := service.NewUserListService(userListRepo, movieRepo)
+	collectionUsecase := service.NewCollectionService(collectionRepo, movieRepo)
+	statsUsecase := service.NewStatsService(statsRepo)
+	personUsecase := service.NewPersonService(personRepo)
+	referenceUsecase := service.NewReferenceService(referenceRepo)
+	commentUsecase := service.NewCommentService(commentRepo, reactionRepo, service.FilterChain{
+		service.NewWordListFilter([]string{"buy now"}, service.FilterReject),
+		service.NewLinkLimitFilter(1, service.FilterHold),
+	})
+	moderationUsecase := service.NewModerationService(
+		commentRepo,
+		flagRepo,
+		moderationRepo,
+		service.DefaultFlagThreshold,
+		[]string{testModeratorID},
+	)
 
 	// Handler.
 	movieHandler := handler.NewMovieHandler(movieUsecase)
-	commentHandler := handler.NewCommentHandler(commentUsecase)
+	commentHandler := handler.NewCommentHandler(commentUsecase, service.NewIdempotencyService(idempotencyRepo, 0))
+	moderationHandler := handler.NewModerationHandler(moderationUsecase)
+	ratingHandler := handler.NewRatingHandler(ratingUsecase)
+	userListHandler := handler.NewUserListHandler(userListUsecase)
+	collectionHandler := handler.NewCollectionHandler(collectionUsecase)
+	statsHandler := handler.NewStatsHandler(statsUsecase)
+	personHandler := handler.NewPersonHandler(personUsecase)
+	referenceHandler := handler.NewReferenceHandler(referenceUsecase)
+
+	// Health checks.
+	healthRegistry := health.NewRegistry(health.DefaultTimeout)
+	healthRegistry.Register(mongodb.PingChecker(db.Client()), mongodb.IndexChecker(db))
+	healthHandler := handler.NewHealthHandler(healthRegistry)
 
 	// Router.
-	router := gin.Default()
-	routes.SetupRoutes(router, movieHandler, commentHandler)
+	appMetrics := metrics.New()
+	router := gin.New()
+	router.Use(
+		middleware.RequestID(),
+		middleware.Logger(slog.New(slog.NewJSONHandler(io.Discard, nil))),
+		middleware.Recovery(),
+		middleware.BodyLimit(1<<20),
+		middleware.Metrics(appMetrics),
+	)
+	routes.SetupRoutes(
+		router,
+		movieHandler,
+		commentHandler,
+		moderationHandler,
+		ratingHandler,
+		userListHandler,
+		collectionHandler,
+		statsHandler,
+		personHandler,
+		referenceHandler,
+		healthHandler,
+		appMetrics.Handler(),
+	)
 
 	return &application{Router: router}
 }